@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// z-значение для 95% доверительного интервала
+const z95 = 1.96
+
+// elo оценивает разницу в рейтинге Эло по результатам первого движка
+// и границы 95% доверительного интервала. Интервал строится по нормальному
+// приближению доли набранных очков и переводится в шкалу Эло.
+func elo(wins, draws, losses int) (diff, low, high float64) {
+	n := float64(wins + draws + losses)
+	if n == 0 {
+		return 0, math.Inf(-1), math.Inf(1)
+	}
+
+	score := (float64(wins) + float64(draws)/2) / n
+	variance := (float64(wins)*math.Pow(1-score, 2) +
+		float64(draws)*math.Pow(0.5-score, 2) +
+		float64(losses)*math.Pow(score, 2)) / n
+	margin := z95 * math.Sqrt(variance/n)
+
+	return scoreToElo(score), scoreToElo(score - margin), scoreToElo(score + margin)
+}
+
+// scoreToElo переводит долю очков в разницу рейтингов; при 0 и 1 она бесконечна
+func scoreToElo(score float64) float64 {
+	switch {
+	case score <= 0:
+		return math.Inf(-1)
+	case score >= 1:
+		return math.Inf(1)
+	}
+	return -400 * math.Log10(1/score-1)
+}
+
+func formatElo(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return fmt.Sprintf("%+.0f", v)
+}
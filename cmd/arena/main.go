@@ -0,0 +1,163 @@
+// Команда arena играет партии между двумя движками по тем же правилам,
+// что и сервер, и сравнивает их силу:
+//
+//	go run ./cmd/arena -a alphabeta:perfect -b mcts:hard -games 100 -width 15
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/game"
+	"time"
+)
+
+// player — участник арены: движок, уровень и накопленная статистика
+type player struct {
+	name   string
+	engine string
+	level  bot.Level
+	moves  int
+	think  time.Duration
+}
+
+func main() {
+	a := flag.String("a", "alphabeta:perfect", "first engine as engine[:level]")
+	b := flag.String("b", "mcts:perfect", "second engine as engine[:level]")
+	games := flag.Int("games", 100, "number of games")
+	mode := flag.String("mode", "standard", "board mode: standard, ultimate, gravity or cube")
+	rules := flag.String("rules", "standard", "rule set")
+	width := flag.Int("width", 0, "board width")
+	height := flag.Int("height", 0, "board height")
+	depth := flag.Int("depth", 0, "board depth (cube)")
+	winLength := flag.Int("win", 0, "line length to win")
+	think := flag.Duration("think", time.Second, "time limit per move")
+	workers := flag.Int("workers", 0, "MCTS goroutines, 0 means one per CPU")
+	seed := flag.Uint64("seed", 1, "base random seed")
+	flag.Parse()
+
+	cfg, err := gameConfig(*mode, *rules, *width, *height, *depth, *winLength)
+	if err != nil {
+		log.Fatal(err)
+	}
+	players := [2]*player{}
+	for i, spec := range []string{*a, *b} {
+		if players[i], err = parsePlayer(spec); err != nil {
+			log.Fatal(err)
+		}
+	}
+	botCfg := bot.Config{ThinkTime: *think, Workers: *workers}
+
+	var wins, draws, losses int // С точки зрения первого движка
+	for i := 0; i < *games; i++ {
+		// Первый ход по очереди: в чётных партиях начинает первый движок
+		order := [2]*player{players[0], players[1]}
+		if i%2 == 1 {
+			order = [2]*player{players[1], players[0]}
+		}
+
+		result, err := play(cfg, botCfg, order, *seed+uint64(i))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		switch {
+		case result.Outcome == game.Draw:
+			draws++
+		case order[result.Winner] == players[0]:
+			wins++
+		default:
+			losses++
+		}
+		fmt.Printf("game %d: %s-%s %s\n", i+1, order[0].name, order[1].name, describe(result, order))
+	}
+
+	fmt.Printf("\n%s vs %s on %s, %d games\n", players[0].name, players[1].name, describeConfig(cfg), *games)
+	fmt.Printf("W/D/L: %d/%d/%d\n", wins, draws, losses)
+	for _, p := range players {
+		fmt.Printf("%s: %d moves, average think time %s\n", p.name, p.moves, average(p.think, p.moves))
+	}
+	diff, low, high := elo(wins, draws, losses)
+	fmt.Printf("Elo difference: %s (95%% CI %s .. %s)\n", formatElo(diff), formatElo(low), formatElo(high))
+}
+
+// gameConfig собирает параметры партии так же, как при создании комнаты
+func gameConfig(mode, rules string, width, height, depth, winLength int) (game.Config, error) {
+	m, err := game.ParseMode(mode)
+	if err != nil {
+		return game.Config{}, err
+	}
+	r, err := game.ParseRules(rules)
+	if err != nil {
+		return game.Config{}, err
+	}
+	cfg := game.Config{Mode: m, Rules: r, Width: width, Height: height, Depth: depth, WinLength: winLength}.WithDefaults()
+	return cfg, cfg.Validate()
+}
+
+// parsePlayer разбирает описание движка вида engine[:level]
+func parsePlayer(spec string) (*player, error) {
+	engine, level, _ := strings.Cut(spec, ":")
+	switch engine {
+	case bot.EngineAuto, bot.EngineAlphaBeta, bot.EngineMCTS:
+	default:
+		return nil, fmt.Errorf("unknown engine %q", engine)
+	}
+	l, err := bot.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return &player{name: spec, engine: engine, level: l}, nil
+}
+
+// play играет одну партию; движки создаются заново, чтобы MCTS не переносил дерево между партиями
+func play(cfg game.Config, botCfg bot.Config, order [2]*player, seed uint64) (game.Result, error) {
+	g, err := game.New(cfg)
+	if err != nil {
+		return game.Result{}, err
+	}
+
+	var engines [2]bot.Engine
+	for i, p := range order {
+		c := botCfg
+		c.Engine = p.engine
+		engines[i] = bot.New(c, cfg, p.level, seed, nil)
+	}
+
+	for !g.Result().Finished() {
+		p := order[g.Turn()]
+		start := time.Now()
+		move, err := engines[g.Turn()].BestMove(context.Background(), g)
+		if err != nil {
+			return game.Result{}, err
+		}
+		p.think += time.Since(start)
+		p.moves++
+
+		if _, err := g.Apply(move); err != nil {
+			return game.Result{}, fmt.Errorf("%s played an illegal move %d: %w", p.name, move.Position, err)
+		}
+	}
+	return g.Result(), nil
+}
+
+func describe(r game.Result, order [2]*player) string {
+	if r.Outcome == game.Draw {
+		return "draw"
+	}
+	return order[r.Winner].name + " won"
+}
+
+func describeConfig(cfg game.Config) string {
+	return fmt.Sprintf("%s %dx%dx%d, %d in a row, %s rules", cfg.Mode, cfg.Width, cfg.Height, cfg.Depth, cfg.WinLength, cfg.Rules)
+}
+
+func average(total time.Duration, n int) time.Duration {
+	if n == 0 {
+		return 0
+	}
+	return (total / time.Duration(n)).Round(time.Microsecond)
+}
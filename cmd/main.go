@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"os"
+	"tic_tac_toe/internal/archive"
 	"tic_tac_toe/internal/config"
+	"tic_tac_toe/internal/repository"
 	"tic_tac_toe/internal/server"
 	redis "tic_tac_toe/pkg/db/redis"
 	"tic_tac_toe/pkg/logger"
@@ -22,13 +24,31 @@ func main() {
 		os.Exit(1)
 	}
 
-	rdb, err := redis.New(cfg.ConfigRedis)
+	// Для локальной разработки комнаты можно держать в памяти и обойтись без Redis
+	var repo repository.RoomStore
+	if cfg.Storage == "memory" {
+		Logger.Info(ctx, "using in-memory room storage")
+		repo = repository.NewMemoryRepository(cfg.RoomTTL)
+	} else {
+		rdb, err := redis.New(cfg.ConfigRedis)
+		if err != nil {
+			Logger.Error(ctx, "redis connection error: "+err.Error())
+			return
+		}
+		repo = repository.NewRoomRepository(rdb, ctx, cfg.RoomTTL)
+	}
+
+	// Законченные партии переживают вытеснение из Redis в SQL-архиве
+	games, err := archive.Open(ctx, cfg.Archive)
 	if err != nil {
-		Logger.Error(ctx, "redis connection error: "+err.Error())
+		Logger.Error(ctx, "archive error: "+err.Error())
 		return
 	}
+	if games != nil {
+		defer games.Close()
+	}
 
-	e := server.New(rdb, Logger)
+	e := server.New(repo, games, Logger, cfg.Config)
 
 	httpServer := server.Start(e, Logger, cfg.HTTPServerPort)
 
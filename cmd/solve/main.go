@@ -0,0 +1,124 @@
+// Команда solve перебирает все достижимые позиции классического поля 3x3
+// и записывает их оценки и лучшие ходы в таблицу для пакета bot:
+//
+//	go generate ./internal/bot
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/game"
+)
+
+func main() {
+	out := flag.String("o", "solved_table.go", "output file")
+	flag.Parse()
+
+	g, err := game.New(game.Classic())
+	if err != nil {
+		log.Fatal(err)
+	}
+	table := make(map[string]bot.Solved)
+	solve(g, table)
+
+	src, err := render(table)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%d positions written to %s", len(table), *out)
+}
+
+// solve оценивает позицию с точки зрения того, кто ходит, и запоминает её
+func solve(g *game.Game, table map[string]bot.Solved) bot.Solved {
+	key, perm, _ := bot.TableKey(g)
+	if s, ok := table[key]; ok {
+		return s
+	}
+
+	// Партия окончена: победить мог только соперник, сделавший последний ход
+	s := bot.Solved{Value: bot.ValueDraw, Best: -1}
+	if r := g.Result(); r.Finished() {
+		if r.Outcome == game.Win {
+			s.Value = bot.ValueLoss
+		}
+		table[key] = s
+		return s
+	}
+
+	first := true
+	for _, m := range g.LegalMoves() {
+		child := g.Clone()
+		if _, err := child.Apply(m); err != nil {
+			log.Fatal(err)
+		}
+		reply := solve(child, table)
+
+		candidate := bot.Solved{Value: bot.ValueDraw, Best: canonical(perm, m.Position)}
+		switch reply.Value {
+		case bot.ValueWin:
+			candidate.Value, candidate.Plies = bot.ValueLoss, reply.Plies+1
+		case bot.ValueLoss:
+			candidate.Value, candidate.Plies = bot.ValueWin, reply.Plies+1
+		}
+		if first || better(candidate, s) {
+			s, first = candidate, false
+		}
+	}
+	table[key] = s
+	return s
+}
+
+// better сравнивает исходы: быстрая победа лучше долгой, долгое поражение — быстрого
+func better(a, b bot.Solved) bool {
+	rank := map[bot.Value]int{bot.ValueLoss: 0, bot.ValueDraw: 1, bot.ValueWin: 2}
+	if rank[a.Value] != rank[b.Value] {
+		return rank[a.Value] > rank[b.Value]
+	}
+	switch a.Value {
+	case bot.ValueWin:
+		return a.Plies < b.Plies
+	case bot.ValueLoss:
+		return a.Plies > b.Plies
+	}
+	return false
+}
+
+// canonical переводит клетку поля в клетку канонической записи
+func canonical(perm []int, pos int) int {
+	for i, p := range perm {
+		if p == pos {
+			return i
+		}
+	}
+	return -1
+}
+
+func render(table map[string]bot.Solved) ([]byte, error) {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := map[bot.Value]string{bot.ValueWin: "ValueWin", bot.ValueDraw: "ValueDraw", bot.ValueLoss: "ValueLoss"}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by cmd/solve; DO NOT EDIT.\n\n")
+	buf.WriteString("package bot\n\n")
+	buf.WriteString("func init() {\n\tsolved = map[string]Solved{\n")
+	for _, key := range keys {
+		s := table[key]
+		fmt.Fprintf(&buf, "\t\t%q: {%s, %d, %d},\n", key, values[s.Value], s.Plies, s.Best)
+	}
+	buf.WriteString("\t}\n}\n")
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,262 @@
+// Package archive хранит законченные партии в SQL-базе: Redis держит только
+// живые комнаты, а по архиву можно искать прошлые партии игрока.
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// Config — параметры подключения к архиву
+type Config struct {
+	Driver string `env:"ARCHIVE_DRIVER" env-default:"sqlite"` // sqlite, postgres или none — без архива
+	DSN    string `env:"ARCHIVE_DSN" env-default:"archive.db"`
+}
+
+// Archive — архив законченных партий
+type Archive struct {
+	db     *sql.DB
+	driver string
+}
+
+// Open подключается к архиву и приводит схему к последней версии.
+// Драйвер none отключает архив: Open возвращает nil, nil.
+func Open(ctx context.Context, cfg Config) (*Archive, error) {
+	if cfg.Driver == "none" {
+		return nil, nil
+	}
+	if cfg.Driver != "sqlite" && cfg.Driver != "postgres" {
+		return nil, fmt.Errorf("unknown archive driver %q", cfg.Driver)
+	}
+
+	db, err := sql.Open(cfg.Driver, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	// SQLite не любит одновременных писателей
+	if cfg.Driver == "sqlite" {
+		db.SetMaxOpenConns(1)
+	}
+
+	a := &Archive{db: db, driver: cfg.Driver}
+	if err := a.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// Close закрывает подключение к архиву
+func (a *Archive) Close() error {
+	return a.db.Close()
+}
+
+// Game — законченная партия
+type Game struct {
+	ID         string    `json:"id"`
+	RoomID     string    `json:"room_id"`
+	Player1    string    `json:"player1"` // Создатель комнаты
+	Player2    string    `json:"player2"`
+	First      string    `json:"first"` // Кто ходил первым
+	Mode       string    `json:"mode"`
+	Rules      string    `json:"rules"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	Depth      int       `json:"depth"`
+	WinLength  int       `json:"win_length"`
+	Status     string    `json:"status"` // finished или tie
+	Winner     string    `json:"winner"`
+	Reason     string    `json:"reason"`
+	Board      string    `json:"board"` // Позиция в конце партии
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Moves      []Move    `json:"moves,omitempty"`
+}
+
+// Move — ход архивной партии
+type Move struct {
+	Seq      int       `json:"seq"`
+	Player   string    `json:"player"`
+	Position int       `json:"position"`
+	Mark     string    `json:"mark"`
+	PlayedAt time.Time `json:"timestamp"`
+}
+
+// Save записывает партию вместе с ходами. Партия с тем же ID заменяется:
+// её могли продолжить отменой последнего хода и закончить заново.
+func (a *Archive) Save(ctx context.Context, g Game) error {
+	return a.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, a.rebind(`INSERT INTO games (
+			id, room_id, player1, player2, first_player, mode, rules, width, height, depth, win_length,
+			status, winner, reason, board, started_at, finished_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			status = excluded.status, winner = excluded.winner, reason = excluded.reason,
+			board = excluded.board, finished_at = excluded.finished_at`),
+			g.ID, g.RoomID, g.Player1, g.Player2, g.First, g.Mode, g.Rules, g.Width, g.Height, g.Depth, g.WinLength,
+			g.Status, g.Winner, g.Reason, g.Board, g.StartedAt.UTC(), g.FinishedAt.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save game: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, a.rebind(`DELETE FROM game_moves WHERE game_id = ?`), g.ID); err != nil {
+			return fmt.Errorf("failed to save moves: %w", err)
+		}
+		for _, m := range g.Moves {
+			_, err := tx.ExecContext(ctx, a.rebind(`INSERT INTO game_moves (game_id, seq, player, position, mark, played_at)
+				VALUES (?, ?, ?, ?, ?, ?)`), g.ID, m.Seq, m.Player, m.Position, m.Mark, m.PlayedAt.UTC())
+			if err != nil {
+				return fmt.Errorf("failed to save moves: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Delete удаляет партию из архива, например, когда её продолжили отменой хода
+func (a *Archive) Delete(ctx context.Context, id string) error {
+	return a.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, a.rebind(`DELETE FROM game_moves WHERE game_id = ?`), id); err != nil {
+			return fmt.Errorf("failed to delete game: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, a.rebind(`DELETE FROM games WHERE id = ?`), id); err != nil {
+			return fmt.Errorf("failed to delete game: %w", err)
+		}
+		return nil
+	})
+}
+
+// Query — условия поиска партий; пустые поля не ограничивают поиск
+type Query struct {
+	Player string    // Любой из двух игроков
+	From   time.Time // Партия закончилась не раньше From
+	To     time.Time // и раньше To
+	Limit  int       // По умолчанию 50
+	Offset int
+}
+
+// Find возвращает партии без ходов, новые первыми
+func (a *Archive) Find(ctx context.Context, q Query) ([]Game, error) {
+	var where []string
+	var args []interface{}
+	if q.Player != "" {
+		where = append(where, "(player1 = ? OR player2 = ?)")
+		args = append(args, q.Player, q.Player)
+	}
+	if !q.From.IsZero() {
+		where = append(where, "finished_at >= ?")
+		args = append(args, q.From.UTC())
+	}
+	if !q.To.IsZero() {
+		where = append(where, "finished_at < ?")
+		args = append(args, q.To.UTC())
+	}
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+
+	query := `SELECT id, room_id, player1, player2, first_player, mode, rules, width, height, depth, win_length,
+		status, winner, reason, board, started_at, finished_at FROM games`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY finished_at DESC, id LIMIT ? OFFSET ?"
+	args = append(args, q.Limit, q.Offset)
+
+	rows, err := a.db.QueryContext(ctx, a.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find games: %w", err)
+	}
+	defer rows.Close()
+
+	games := []Game{}
+	for rows.Next() {
+		var g Game
+		err := rows.Scan(&g.ID, &g.RoomID, &g.Player1, &g.Player2, &g.First, &g.Mode, &g.Rules,
+			&g.Width, &g.Height, &g.Depth, &g.WinLength, &g.Status, &g.Winner, &g.Reason, &g.Board,
+			&g.StartedAt, &g.FinishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read game: %w", err)
+		}
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find games: %w", err)
+	}
+	return games, nil
+}
+
+// Get возвращает партию с ходами; nil, если такой партии нет
+func (a *Archive) Get(ctx context.Context, id string) (*Game, error) {
+	var g Game
+	err := a.db.QueryRowContext(ctx, a.rebind(`SELECT id, room_id, player1, player2, first_player, mode, rules,
+		width, height, depth, win_length, status, winner, reason, board, started_at, finished_at
+		FROM games WHERE id = ?`), id).Scan(&g.ID, &g.RoomID, &g.Player1, &g.Player2, &g.First, &g.Mode, &g.Rules,
+		&g.Width, &g.Height, &g.Depth, &g.WinLength, &g.Status, &g.Winner, &g.Reason, &g.Board,
+		&g.StartedAt, &g.FinishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game: %w", err)
+	}
+
+	rows, err := a.db.QueryContext(ctx, a.rebind(`SELECT seq, player, position, mark, played_at
+		FROM game_moves WHERE game_id = ? ORDER BY seq`), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moves: %w", err)
+	}
+	defer rows.Close()
+
+	g.Moves = []Move{}
+	for rows.Next() {
+		var m Move
+		if err := rows.Scan(&m.Seq, &m.Player, &m.Position, &m.Mark, &m.PlayedAt); err != nil {
+			return nil, fmt.Errorf("failed to read move: %w", err)
+		}
+		g.Moves = append(g.Moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get moves: %w", err)
+	}
+	return &g, nil
+}
+
+// inTx выполняет fn в транзакции и откатывает её при ошибке
+func (a *Archive) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// rebind заменяет параметры ? на $1, $2, ... для Postgres
+func (a *Archive) rebind(query string) string {
+	if a.driver != "postgres" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
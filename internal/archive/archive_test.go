@@ -0,0 +1,192 @@
+package archive
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestArchive открывает пустой архив в памяти
+func newTestArchive(t *testing.T) *Archive {
+	t.Helper()
+	a, err := Open(context.Background(), Config{Driver: "sqlite", DSN: ":memory:"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { a.Close() })
+	return a
+}
+
+// game возвращает партию player1 против player2, закончившуюся в момент finished
+func game(id, player1, player2 string, finished time.Time, moves ...Move) Game {
+	return Game{
+		ID:         id,
+		RoomID:     "room-" + id,
+		Player1:    player1,
+		Player2:    player2,
+		First:      player1,
+		Mode:       "standard",
+		Rules:      "standard",
+		Width:      3,
+		Height:     3,
+		Depth:      1,
+		WinLength:  3,
+		Status:     "finished",
+		Winner:     player1,
+		Reason:     "line",
+		Board:      "XXXOO    ",
+		StartedAt:  finished.Add(-time.Minute),
+		FinishedAt: finished,
+		Moves:      moves,
+	}
+}
+
+func ids(games []Game) []string {
+	out := make([]string, len(games))
+	for i, g := range games {
+		out[i] = g.ID
+	}
+	return out
+}
+
+func TestMigrateTwice(t *testing.T) {
+	a := newTestArchive(t)
+	ctx := context.Background()
+	if err := a.Save(ctx, game("g1", "alice", "bob", day)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Повторный запуск не применяет миграции заново и не трогает данные
+	if err := a.migrate(ctx); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+	var applied int
+	if err := a.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(migrations) {
+		t.Errorf("%d migrations recorded, want %d", applied, len(migrations))
+	}
+	if g, err := a.Get(ctx, "g1"); err != nil || g == nil {
+		t.Errorf("game after second migrate: %v, %v", g, err)
+	}
+}
+
+func TestSaveReplaces(t *testing.T) {
+	a := newTestArchive(t)
+	ctx := context.Background()
+	first := game("g1", "alice", "bob", day,
+		Move{Seq: 1, Player: "alice", Position: 0, Mark: "X", PlayedAt: day.Add(-50 * time.Second)},
+		Move{Seq: 2, Player: "bob", Position: 4, Mark: "O", PlayedAt: day.Add(-40 * time.Second)},
+		Move{Seq: 3, Player: "alice", Position: 8, Mark: "X", PlayedAt: day.Add(-30 * time.Second)},
+	)
+	if err := a.Save(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+
+	// Партию продолжили отменой хода и закончили иначе: ходы заменяются целиком
+	second := game("g1", "alice", "bob", day.Add(time.Hour),
+		Move{Seq: 1, Player: "alice", Position: 0, Mark: "X", PlayedAt: day.Add(-50 * time.Second)},
+		Move{Seq: 2, Player: "bob", Position: 2, Mark: "O", PlayedAt: day.Add(time.Hour)},
+	)
+	second.Status, second.Winner, second.Reason = "tie", "", "agreement"
+	if err := a.Save(ctx, second); err != nil {
+		t.Fatalf("second save: %v", err)
+	}
+
+	g, err := a.Get(ctx, "g1")
+	if err != nil || g == nil {
+		t.Fatalf("Get: %v, %v", g, err)
+	}
+	if g.Status != "tie" || g.Winner != "" || g.Reason != "agreement" || !g.FinishedAt.Equal(day.Add(time.Hour)) {
+		t.Errorf("game = %+v", g)
+	}
+	if len(g.Moves) != 2 || g.Moves[1].Position != 2 || !g.Moves[1].PlayedAt.Equal(day.Add(time.Hour)) {
+		t.Errorf("moves = %+v", g.Moves)
+	}
+	if games, _ := a.Find(ctx, Query{}); len(games) != 1 {
+		t.Errorf("found %v", ids(games))
+	}
+
+	if err := a.Delete(ctx, "g1"); err != nil {
+		t.Fatal(err)
+	}
+	if g, err := a.Get(ctx, "g1"); err != nil || g != nil {
+		t.Errorf("deleted game: %v, %v", g, err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	a := newTestArchive(t)
+	ctx := context.Background()
+	for _, g := range []Game{
+		game("a", "alice", "bob", day.Add(time.Hour)),
+		game("b", "carol", "alice", day.Add(2*time.Hour)),
+		game("c", "bob", "carol", day.Add(3*time.Hour)),
+		game("d", "alice", "carol", day.Add(24*time.Hour)),
+		// Закончилась одновременно с b: порядок решает ID
+		game("bb", "bob", "alice", day.Add(2*time.Hour)),
+	} {
+		if err := a.Save(ctx, g); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all, newest first", Query{}, []string{"d", "c", "b", "bb", "a"}},
+		{"player1 or player2", Query{Player: "alice"}, []string{"d", "b", "bb", "a"}},
+		{"unknown player", Query{Player: "dave"}, []string{}},
+		{"from is inclusive", Query{From: day.Add(2 * time.Hour)}, []string{"d", "c", "b", "bb"}},
+		{"to is exclusive", Query{To: day.Add(2 * time.Hour)}, []string{"a"}},
+		{"player for a day", Query{Player: "carol", From: day, To: day.Add(24 * time.Hour)}, []string{"c", "b"}},
+		{"limit", Query{Limit: 2}, []string{"d", "c"}},
+		{"offset", Query{Limit: 2, Offset: 2}, []string{"b", "bb"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			games, err := a.Find(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ids(games)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Find() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Find() = %v, want %v", got, tt.want)
+				}
+			}
+			for _, g := range games {
+				if g.Moves != nil {
+					t.Errorf("game %s found with moves", g.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	a := newTestArchive(t)
+	g, err := a.Get(context.Background(), "missing")
+	if err != nil || g != nil {
+		t.Errorf("Get(missing) = %v, %v; want nil, nil", g, err)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := `SELECT * FROM games WHERE (player1 = ? OR player2 = ?) LIMIT ?`
+	if got := (&Archive{driver: "sqlite"}).rebind(query); got != query {
+		t.Errorf("sqlite rebind = %q", got)
+	}
+	want := `SELECT * FROM games WHERE (player1 = $1 OR player2 = $2) LIMIT $3`
+	if got := (&Archive{driver: "postgres"}).rebind(query); got != want {
+		t.Errorf("postgres rebind = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations — изменения схемы по порядку; номер миграции — её индекс плюс один.
+// Уже выпущенные миграции не меняются, новые дописываются в конец. Схема
+// использует только общие для SQLite и Postgres типы и конструкции.
+var migrations = []string{
+	`CREATE TABLE games (
+		id           TEXT PRIMARY KEY,
+		room_id      TEXT NOT NULL,
+		player1      TEXT NOT NULL,
+		player2      TEXT NOT NULL,
+		first_player TEXT NOT NULL,
+		mode         TEXT NOT NULL,
+		rules        TEXT NOT NULL,
+		width        INTEGER NOT NULL,
+		height       INTEGER NOT NULL,
+		depth        INTEGER NOT NULL,
+		win_length   INTEGER NOT NULL,
+		status       TEXT NOT NULL,
+		winner       TEXT NOT NULL,
+		reason       TEXT NOT NULL,
+		board        TEXT NOT NULL,
+		started_at   TIMESTAMP NOT NULL,
+		finished_at  TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE game_moves (
+		game_id   TEXT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+		seq       INTEGER NOT NULL,
+		player    TEXT NOT NULL,
+		position  INTEGER NOT NULL,
+		mark      TEXT NOT NULL,
+		played_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (game_id, seq)
+	)`,
+	// Партии ищут по игроку за период
+	`CREATE INDEX games_player1_finished ON games (player1, finished_at)`,
+	`CREATE INDEX games_player2_finished ON games (player2, finished_at)`,
+}
+
+// migrate применяет миграции, которых ещё нет в таблице schema_migrations.
+// Каждая миграция выполняется в своей транзакции вместе с отметкой о ней.
+func (a *Archive) migrate(ctx context.Context) error {
+	_, err := a.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	var applied int
+	if err := a.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&applied); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := applied; i < len(migrations); i++ {
+		err := a.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, a.rebind(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`), i+1, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
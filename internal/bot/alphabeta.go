@@ -0,0 +1,307 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"tic_tac_toe/internal/game"
+	"time"
+)
+
+// Оценка выигранной позиции; чем быстрее победа, тем выше оценка
+const (
+	winScore  = 1_000_000
+	mateBound = winScore - 10_000
+	infinity  = winScore + 1
+)
+
+var errTimeout = errors.New("search timed out")
+
+// AlphaBeta — перебор с альфа-бета отсечением, итеративным углублением
+// и таблицей транспозиций по строке поля. На маленьких полях перебор
+// доходит до конца партии, на больших — до истечения лимита времени.
+type AlphaBeta struct {
+	MaxDepth int           // Ограничение глубины, 0 — до конца партии
+	Budget   time.Duration // Лимит времени на ход, 0 — без ограничения
+}
+
+// bound — тип оценки в таблице транспозиций
+type bound int8
+
+const (
+	exact bound = iota
+	lower
+	upper
+)
+
+type entry struct {
+	depth int
+	score int
+	bound bound
+	move  game.Move
+}
+
+type search struct {
+	ctx   context.Context
+	table map[string]entry
+	eval  *evaluator
+	rank  []int // Порядок клеток: ближе к центру — раньше
+	nodes int
+}
+
+// BestMove выбирает ход для текущего игрока
+func (a *AlphaBeta) BestMove(ctx context.Context, g *game.Game) (game.Move, error) {
+	if m, ok := tableMove(g); ok && a.complete(g) {
+		return m, nil
+	}
+	moves, err := a.Analyse(ctx, g)
+	if err != nil {
+		return game.Move{}, err
+	}
+	return moves[0].Move, nil
+}
+
+// Analyse оценивает все ходы текущего игрока на наибольшую глубину,
+// которую удалось просчитать. Оценка дана с точки зрения сделавшего ход.
+func (a *AlphaBeta) Analyse(ctx context.Context, g *game.Game) ([]Scored, error) {
+	if len(g.LegalMoves()) == 0 {
+		return nil, ErrNoMoves
+	}
+	if evals, ok := tableEvaluations(g); ok && a.complete(g) {
+		scored := make([]Scored, len(evals))
+		for i, e := range evals {
+			scored[i] = Scored{Move: e.Move, Score: evaluationScore(e)}
+		}
+		return scored, nil
+	}
+	scored, _ := a.analyse(ctx, g, candidates(g), false)
+	return scored, nil
+}
+
+// complete сообщает, что ограничение глубины не мешает перебору дойти до конца
+// партии, а значит, готовый ответ из таблицы совпал бы с результатом перебора
+func (a *AlphaBeta) complete(g *game.Game) bool {
+	return a.MaxDepth == 0 || a.MaxDepth >= remaining(g)
+}
+
+// analyse углубляет перебор, пока хватает глубины и времени. Если exhaustive
+// не задан, поиск останавливается, как только исход лучшего хода известен;
+// иначе — только когда известны исходы всех ходов. Второй результат
+// сообщает, что оценки всех ходов точные.
+func (a *AlphaBeta) analyse(ctx context.Context, g *game.Game, moves []game.Move, exhaustive bool) ([]Scored, bool) {
+	if a.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Budget)
+		defer cancel()
+	}
+
+	s := newSearch(ctx, g)
+	moves = s.ordered(moves, nil)
+	maxDepth := remaining(g)
+	if a.MaxDepth > 0 && a.MaxDepth < maxDepth {
+		maxDepth = a.MaxDepth
+	}
+
+	// Без единой завершённой итерации ходы остаются в порядке «ближе к центру»
+	scored := make([]Scored, len(moves))
+	for i, m := range moves {
+		scored[i] = Scored{Move: m}
+	}
+
+	for depth := 1; depth <= maxDepth; depth++ {
+		next, err := s.root(g, moves, depth)
+		if err != nil {
+			return scored, false
+		}
+		scored = next
+
+		// Перебор дошёл до конца партии — все оценки точные
+		if depth == remaining(g) {
+			return scored, true
+		}
+
+		// Исход уже известен — глубже искать незачем
+		decided := 0
+		for _, sc := range scored {
+			if decisive(sc.Score) {
+				decided++
+			}
+		}
+		if decided == len(scored) {
+			return scored, true
+		}
+		if !exhaustive && decisive(scored[0].Score) {
+			return scored, false
+		}
+	}
+	return scored, false
+}
+
+// decisive сообщает, что оценка означает форсированную победу или поражение
+func decisive(score int) bool {
+	return score >= mateBound || score <= -mateBound
+}
+
+func newSearch(ctx context.Context, g *game.Game) *search {
+	cfg := g.Config()
+	s := &search{
+		ctx:   ctx,
+		table: make(map[string]entry),
+		eval:  newEvaluator(g),
+		rank:  make([]int, cfg.Cells()),
+	}
+
+	// Клетки ближе к центру поля обычно сильнее, их смотрим первыми
+	for pos := range s.rank {
+		s.rank[pos] = centerDistance(cfg, pos)
+	}
+	return s
+}
+
+// root оценивает каждый ход корня полным окном, чтобы получить точные оценки всех ходов
+func (s *search) root(g *game.Game, moves []game.Move, depth int) ([]Scored, error) {
+	scored := make([]Scored, 0, len(moves))
+	for _, m := range moves {
+		child := g.Clone()
+		if _, err := child.Apply(m); err != nil {
+			return nil, err
+		}
+		v, err := s.negamax(child, depth-1, 1, -infinity, infinity)
+		if err != nil {
+			return nil, err
+		}
+		scored = append(scored, Scored{Move: m, Score: -v})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	return scored, nil
+}
+
+// negamax возвращает оценку позиции с точки зрения игрока, который ходит
+func (s *search) negamax(g *game.Game, depth, ply, alpha, beta int) (int, error) {
+	if r := g.Result(); r.Finished() {
+		return terminal(r, g.Turn(), ply), nil
+	}
+	if depth == 0 {
+		return s.eval.score(g), nil
+	}
+
+	s.nodes++
+	if s.nodes%1024 == 0 && s.ctx.Err() != nil {
+		return 0, errTimeout
+	}
+
+	key := positionKey(g)
+	var ttMove *game.Move
+	if e, ok := s.table[key]; ok {
+		ttMove = &e.move
+		if e.depth >= depth {
+			score := fromTable(e.score, ply)
+			switch {
+			case e.bound == exact:
+				return score, nil
+			case e.bound == lower && score >= beta:
+				return score, nil
+			case e.bound == upper && score <= alpha:
+				return score, nil
+			}
+		}
+	}
+
+	origAlpha := alpha
+	best := -infinity
+	var bestMove game.Move
+	for _, m := range s.ordered(candidates(g), ttMove) {
+		child := g.Clone()
+		if _, err := child.Apply(m); err != nil {
+			return 0, err
+		}
+		v, err := s.negamax(child, depth-1, ply+1, -beta, -alpha)
+		if err != nil {
+			return 0, err
+		}
+		v = -v
+
+		if v > best {
+			best, bestMove = v, m
+		}
+		if v > alpha {
+			alpha = v
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	e := entry{depth: depth, score: toTable(best, ply), bound: exact, move: bestMove}
+	if best <= origAlpha {
+		e.bound = upper
+	} else if best >= beta {
+		e.bound = lower
+	}
+	s.table[key] = e
+	return best, nil
+}
+
+// ordered сортирует ходы: сначала лучший ход из таблицы, затем ближе к центру
+func (s *search) ordered(moves []game.Move, first *game.Move) []game.Move {
+	out := append([]game.Move(nil), moves...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if first != nil && (out[i] == *first) != (out[j] == *first) {
+			return out[i] == *first
+		}
+		return s.rank[out[i].Position] < s.rank[out[j].Position]
+	})
+	return out
+}
+
+// terminal оценивает законченную партию с точки зрения игрока to
+func terminal(r game.Result, to game.Player, ply int) int {
+	if r.Outcome != game.Win {
+		return 0
+	}
+	if r.Winner == to {
+		return winScore - ply
+	}
+	return -(winScore - ply)
+}
+
+// Оценки выигрыша в таблице хранятся относительно узла, а не корня,
+// иначе одна и та же позиция на разной глубине получала бы разные оценки
+func toTable(score, ply int) int {
+	switch {
+	case score >= mateBound:
+		return score + ply
+	case score <= -mateBound:
+		return score - ply
+	}
+	return score
+}
+
+func fromTable(score, ply int) int {
+	switch {
+	case score >= mateBound:
+		return score - ply
+	case score <= -mateBound:
+		return score + ply
+	}
+	return score
+}
+
+// positionKey — ключ таблицы транспозиций: поле, очередь хода и обязательное подполе
+func positionKey(g *game.Game) string {
+	return g.Board() + string(rune('0'+int(g.Turn()))) + string(rune('0'+g.Next()+1))
+}
+
+// remaining возвращает количество свободных клеток — верхнюю границу длины партии
+func remaining(g *game.Game) int {
+	n := 0
+	for _, c := range g.Board() {
+		if game.Mark(c) == game.Empty {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"tic_tac_toe/internal/game"
+)
+
+// Book — дебютная книга: заранее найденные ходы для начальных позиций
+// больших полей. Позиции хранятся в канонической записи, поэтому достаточно
+// одной позиции из каждой группы симметричных.
+type Book struct {
+	moves map[string]game.Move
+}
+
+// BookEntry — запись книги в файле. Пустые клетки поля можно записывать точкой.
+type BookEntry struct {
+	Mode      game.Mode   `json:"mode"`
+	Width     int         `json:"width"`
+	Height    int         `json:"height"`
+	Depth     int         `json:"depth"`
+	WinLength int         `json:"win_length"`
+	Rules     game.Rules  `json:"rules"`
+	Board     string      `json:"board"`
+	Turn      game.Player `json:"turn"`       // 0 — ходит первый игрок, 1 — второй
+	NextBoard *int        `json:"next_board"` // Обязательное подполе, по умолчанию любое
+	Position  int         `json:"position"`
+	Mark      string      `json:"mark"` // Нужен, только если игроку доступны оба знака
+}
+
+// LoadBook читает книгу из JSON-файла со списком записей.
+// Пустой путь означает, что книги нет.
+func LoadBook(path string) (*Book, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []BookEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	b := &Book{moves: make(map[string]game.Move, len(entries))}
+	for i, e := range entries {
+		if err := b.add(e); err != nil {
+			return nil, fmt.Errorf("opening book entry %d: %w", i, err)
+		}
+	}
+	return b, nil
+}
+
+// add проверяет запись и запоминает ход в канонической записи позиции
+func (b *Book) add(e BookEntry) error {
+	cfg := game.Config{
+		Mode:      e.Mode,
+		Width:     e.Width,
+		Height:    e.Height,
+		Depth:     e.Depth,
+		WinLength: e.WinLength,
+		Rules:     e.Rules,
+	}.WithDefaults()
+
+	next := -1
+	if e.NextBoard != nil {
+		next = *e.NextBoard
+	}
+	g, err := game.Parse(cfg, game.State{Board: strings.ReplaceAll(e.Board, ".", " "), Turn: e.Turn, Next: next})
+	if err != nil {
+		return err
+	}
+	move := game.Move{Position: e.Position, Mark: game.Empty}
+	if e.Mark != "" {
+		if move.Mark, err = game.ParseMark(e.Mark); err != nil {
+			return err
+		}
+	}
+	if _, err := g.Clone().Apply(move); err != nil {
+		return err
+	}
+
+	key, perm := bookKey(g)
+	for i, p := range perm {
+		if p == e.Position {
+			move.Position = i
+		}
+	}
+	b.moves[key] = move
+	return nil
+}
+
+// Move возвращает ход из книги для текущей позиции
+func (b *Book) Move(g *game.Game) (game.Move, bool) {
+	if b == nil {
+		return game.Move{}, false
+	}
+	key, perm := bookKey(g)
+	move, ok := b.moves[key]
+	if !ok {
+		return game.Move{}, false
+	}
+	move.Position = perm[move.Position]
+	if marks := g.Marks(g.Turn()); move.Mark == game.Empty && len(marks) == 1 {
+		move.Mark = marks[0]
+	}
+	return move, true
+}
+
+// bookKey — параметры поля и каноническая позиция с очередью хода и обязательным подполем
+func bookKey(g *game.Game) (string, []int) {
+	cfg := g.Config()
+	board, perm := cfg.Canonical(g.Board())
+	return fmt.Sprintf("%s/%dx%dx%d/%d/%s/%s%d%d",
+		cfg.Mode, cfg.Width, cfg.Height, max(cfg.Depth, 1), cfg.WinLength, cfg.Rules,
+		board, g.Turn(), g.Next()+1), perm
+}
+
+// Opening — движок, который сначала ищет ход в дебютной книге
+type Opening struct {
+	Book   *Book
+	Engine Engine
+}
+
+// BestMove отвечает ходом из книги, а вне книги передаёт позицию движку
+func (o *Opening) BestMove(ctx context.Context, g *game.Game) (game.Move, error) {
+	if m, ok := o.Book.Move(g); ok {
+		return m, nil
+	}
+	return o.Engine.BestMove(ctx, g)
+}
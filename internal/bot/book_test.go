@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"tic_tac_toe/internal/game"
+)
+
+func TestBookRespectsLevel(t *testing.T) {
+	cfg := game.Config{Mode: game.Standard, Width: 4, Height: 4, Depth: 1, WinLength: 3, Rules: game.StandardRules}
+	// Угол на пустом поле — ход, который перебор сам бы не выбрал
+	book := &Book{moves: make(map[string]game.Move)}
+	if err := book.add(BookEntry{Mode: cfg.Mode, Width: 4, Height: 4, WinLength: 3, Board: "................", Position: 0}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	bookMoves := func(level Level) int {
+		n := 0
+		for i := 0; i < 20; i++ {
+			g, err := game.New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			engine := New(Config{Engine: EngineAlphaBeta}, cfg, level, Seed(fmt.Sprintf("room-%d", i)), book)
+			m, err := engine.BestMove(context.Background(), g)
+			if err != nil {
+				t.Fatalf("BestMove: %v", err)
+			}
+			if m.Position == 0 || m.Position == 3 || m.Position == 12 || m.Position == 15 {
+				n++
+			}
+		}
+		return n
+	}
+
+	if n := bookMoves(Perfect); n != 20 {
+		t.Errorf("perfect bot followed the book in %d of 20 games", n)
+	}
+	// Слабый бот ошибается в дебюте так же, как и дальше
+	if n := bookMoves(Easy); n == 20 {
+		t.Errorf("easy bot always followed the book")
+	}
+}
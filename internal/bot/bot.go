@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"tic_tac_toe/internal/game"
+	"time"
+)
+
+// Name — имя, под которым бот занимает место второго игрока в комнате
+const Name = "bot"
+
+var (
+	// ErrNoMoves возвращается, если в позиции нет допустимых ходов
+	ErrNoMoves = errors.New("no legal moves")
+	// ErrUnknownLevel возвращается для неизвестного уровня сложности
+	ErrUnknownLevel = errors.New("unknown bot level")
+)
+
+// Engine — движок, выбирающий ход за текущего игрока
+type Engine interface {
+	BestMove(ctx context.Context, g *game.Game) (game.Move, error)
+}
+
+// Analyser — движок, умеющий упорядочить все ходы от лучшего к худшему
+type Analyser interface {
+	Engine
+	Analyse(ctx context.Context, g *game.Game) ([]Scored, error)
+}
+
+// Scored — ход с оценкой движка: чем больше, тем лучше
+type Scored struct {
+	Move  game.Move
+	Score int
+}
+
+// Движки, которыми может играть бот
+const (
+	EngineAuto      = "auto"      // Перебор на маленьких полях, MCTS на больших
+	EngineAlphaBeta = "alphabeta" // Всегда перебор
+	EngineMCTS      = "mcts"      // Всегда MCTS
+)
+
+// Поле, начиная с которого в автоматическом режиме играет MCTS
+const mctsCells = 49
+
+// Config — настройки ботов
+type Config struct {
+	ThinkTime time.Duration `env:"BOT_THINK_TIME" env-default:"2s"` // Лимит времени на ход на больших полях
+	Engine    string        `env:"BOT_ENGINE" env-default:"auto"`   // auto, alphabeta или mcts
+	Workers   int           `env:"BOT_WORKERS" env-default:"0"`     // Горутины MCTS, 0 — по числу процессоров
+	Book      string        `env:"BOT_OPENING_BOOK" env-default:""` // JSON-файл дебютной книги, пусто — без книги
+}
+
+// New возвращает движок, которым бот играет на заданном поле и уровне.
+// Дебютная книга book может быть nil.
+func New(cfg Config, gameCfg game.Config, level Level, seed uint64, book *Book) Engine {
+	d := Levels[level]
+
+	var search Analyser
+	if cfg.Engine == EngineMCTS || (cfg.Engine != EngineAlphaBeta && gameCfg.Cells() > mctsCells) {
+		search = &MCTS{Iterations: d.Iterations, Budget: cfg.ThinkTime, Workers: cfg.Workers, Seed: seed}
+	} else {
+		search = &AlphaBeta{MaxDepth: d.MaxDepth, Budget: cfg.ThinkTime}
+	}
+
+	if d.Random == 0 && d.SecondBest == 0 {
+		if book != nil {
+			return &Opening{Book: book, Engine: search}
+		}
+		return search
+	}
+	// Ослабленный бот ошибается и в дебюте, поэтому книгу спрашивает сам
+	return &Handicapped{Search: search, Random: d.Random, SecondBest: d.SecondBest, Seed: seed, Book: book}
+}
+
+// Pool хранит движки ботов по комнатам, чтобы MCTS переиспользовал дерево между ходами
+type Pool struct {
+	cfg     Config
+	book    *Book
+	mu      sync.Mutex
+	engines map[string]Engine
+}
+
+// NewPool создаёт пул движков; book может быть nil
+func NewPool(cfg Config, book *Book) *Pool {
+	return &Pool{cfg: cfg, book: book, engines: make(map[string]Engine)}
+}
+
+// Config возвращает настройки ботов
+func (p *Pool) Config() Config {
+	return p.cfg
+}
+
+// Engine возвращает движок комнаты, создавая его при первом обращении
+func (p *Pool) Engine(roomID string, gameCfg game.Config, level Level) Engine {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.engines[roomID]
+	if !ok {
+		e = New(p.cfg, gameCfg, level, Seed(roomID), p.book)
+		p.engines[roomID] = e
+	}
+	return e
+}
+
+// Release забывает движок комнаты, например, когда партия закончилась
+func (p *Pool) Release(roomID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.engines, roomID)
+}
@@ -0,0 +1,63 @@
+package bot
+
+import "tic_tac_toe/internal/game"
+
+// Веса отрезков по количеству знаков одного игрока на них
+var windowWeights = []int{0, 1, 10, 100, 1_000, 10_000}
+
+// evaluator — эвристическая оценка незаконченной позиции по отрезкам,
+// на которых ещё может образоваться линия. Оценка осмысленна, только если
+// у каждого игрока свой знак; иначе она всегда нулевая.
+type evaluator struct {
+	windows [][]int
+	marks   [2]game.Mark
+	sign    int // -1 в мизере: собственная линия там — проигрыш
+}
+
+func newEvaluator(g *game.Game) *evaluator {
+	e := &evaluator{sign: 1}
+
+	first, second := g.Marks(game.First), g.Marks(game.Second)
+	if len(first) != 1 || len(second) != 1 || first[0] == second[0] {
+		return e
+	}
+	e.marks = [2]game.Mark{first[0], second[0]}
+	e.windows = g.Config().Windows()
+	if g.Config().Rules == game.MisereRules {
+		e.sign = -1
+	}
+	return e
+}
+
+// score возвращает оценку с точки зрения игрока, который ходит
+func (e *evaluator) score(g *game.Game) int {
+	if e.windows == nil {
+		return 0
+	}
+
+	board := g.Board()
+	me, opp := e.marks[g.Turn()], e.marks[g.Turn().Other()]
+	score := 0
+	for _, w := range e.windows {
+		mine, theirs := 0, 0
+		for _, pos := range w {
+			switch game.Mark(board[pos]) {
+			case me:
+				mine++
+			case opp:
+				theirs++
+			}
+		}
+		switch {
+		case mine > 0 && theirs > 0:
+		case mine > 0:
+			score += windowWeights[min(mine, len(windowWeights)-1)]
+		case theirs > 0:
+			score -= windowWeights[min(theirs, len(windowWeights)-1)]
+		}
+	}
+
+	// Эвристика не должна перевешивать найденную победу
+	score = max(min(score, mateBound/2), -mateBound/2)
+	return e.sign * score
+}
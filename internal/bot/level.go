@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand/v2"
+	"tic_tac_toe/internal/game"
+)
+
+// Level — уровень сложности бота, как он хранится в комнате
+type Level string
+
+const (
+	Easy    Level = "easy"
+	Medium  Level = "medium"
+	Hard    Level = "hard"
+	Perfect Level = "perfect"
+)
+
+// ParseLevel разбирает уровень сложности; пустая строка — идеальный игрок
+func ParseLevel(s string) (Level, error) {
+	switch Level(s) {
+	case "":
+		return Perfect, nil
+	case Easy, Medium, Hard, Perfect:
+		return Level(s), nil
+	}
+	return "", ErrUnknownLevel
+}
+
+// Difficulty — насколько бот ослаблен: глубина перебора, число итераций MCTS
+// и вероятности ошибок
+type Difficulty struct {
+	MaxDepth   int     // Глубина перебора, 0 — без ограничения
+	Iterations int     // Итерации MCTS на ход, 0 — пока не кончится время
+	Random     float64 // Вероятность сыграть случайный ход
+	SecondBest float64 // Вероятность сыграть второй по силе ход
+}
+
+// Levels — параметры уровней сложности
+var Levels = map[Level]Difficulty{
+	Easy:    {MaxDepth: 1, Iterations: 200, Random: 0.3, SecondBest: 0.3},
+	Medium:  {MaxDepth: 3, Iterations: 2_000, Random: 0.1, SecondBest: 0.2},
+	Hard:    {MaxDepth: 5, Iterations: 20_000, SecondBest: 0.1},
+	Perfect: {},
+}
+
+// Handicapped — перебор, который иногда намеренно ошибается.
+// Случайность определяется зерном и номером хода, поэтому одна и та же
+// партия в одной и той же комнате воспроизводится ход в ход.
+type Handicapped struct {
+	Search     Analyser
+	Random     float64
+	SecondBest float64
+	Seed       uint64
+	Book       *Book // Ход из книги считается лучшим, ошибки случаются и в дебюте; может быть nil
+}
+
+// BestMove выбирает ход с учётом заданных вероятностей ошибок
+func (h *Handicapped) BestMove(ctx context.Context, g *game.Game) (game.Move, error) {
+	rnd := rand.New(rand.NewPCG(h.Seed, uint64(plies(g))))
+	roll := rnd.Float64()
+	if roll >= h.Random+h.SecondBest {
+		if m, ok := h.Book.Move(g); ok {
+			return m, nil
+		}
+	}
+
+	scored, err := h.Search.Analyse(ctx, g)
+	if err != nil {
+		return game.Move{}, err
+	}
+	switch {
+	case roll < h.Random:
+		return scored[rnd.IntN(len(scored))].Move, nil
+	case roll < h.Random+h.SecondBest && len(scored) > 1:
+		return scored[1].Move, nil
+	}
+	return scored[0].Move, nil
+}
+
+// Seed выводит зерно случайности из идентификатора комнаты
+func Seed(roomID string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(roomID))
+	return h.Sum64()
+}
+
+// plies возвращает количество сделанных ходов
+func plies(g *game.Game) int {
+	return g.Config().Cells() - remaining(g)
+}
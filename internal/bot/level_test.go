@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"tic_tac_toe/internal/game"
+)
+
+// selfPlay доигрывает партию, в которой за обе стороны ходят движки одного
+// уровня с одним зерном, и возвращает сыгранные ходы
+func selfPlay(t *testing.T, cfg Config, gameCfg game.Config, level Level, seed uint64) []game.Move {
+	t.Helper()
+	g, err := game.New(gameCfg)
+	if err != nil {
+		t.Fatalf("game.New: %v", err)
+	}
+	engines := []Engine{New(cfg, gameCfg, level, seed, nil), New(cfg, gameCfg, level, seed, nil)}
+
+	var moves []game.Move
+	for !g.Result().Finished() {
+		m, err := engines[g.Turn()].BestMove(context.Background(), g)
+		if err != nil {
+			t.Fatalf("move %d: %v", len(moves)+1, err)
+		}
+		if _, err := g.Apply(m); err != nil {
+			t.Fatalf("move %d (%+v): %v", len(moves)+1, m, err)
+		}
+		moves = append(moves, m)
+	}
+	return moves
+}
+
+func TestLevelsReproducible(t *testing.T) {
+	// Без лимита времени результат зависит только от зерна: MCTS
+	// ограничен итерациями и играет в одну горутину
+	alphaBeta := Config{Engine: EngineAlphaBeta}
+	mcts := Config{Engine: EngineMCTS, Workers: 1}
+	fourByFour := game.Config{Mode: game.Standard, Width: 4, Height: 4, Depth: 1, WinLength: 3, Rules: game.StandardRules}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		gameCfg game.Config
+	}{
+		{"alphabeta 3x3", alphaBeta, game.Classic()},
+		{"alphabeta 4x4", alphaBeta, fourByFour},
+		{"mcts 4x4", mcts, fourByFour},
+	}
+	seed := Seed("room-test")
+	for _, tt := range tests {
+		for _, level := range []Level{Easy, Medium, Hard, Perfect} {
+			if tt.cfg.Engine == EngineMCTS && level == Perfect {
+				// Идеальный уровень без лимитов MCTS считает слишком долго для теста
+				continue
+			}
+			t.Run(tt.name+"/"+string(level), func(t *testing.T) {
+				first := selfPlay(t, tt.cfg, tt.gameCfg, level, seed)
+				second := selfPlay(t, tt.cfg, tt.gameCfg, level, seed)
+				if !reflect.DeepEqual(first, second) {
+					t.Errorf("same seed, different games:\n%v\n%v", first, second)
+				}
+			})
+		}
+	}
+}
+
+func TestHandicappedSeed(t *testing.T) {
+	// Слабый уровень с разными зёрнами должен играть по-разному, иначе
+	// случайность не зависит от комнаты
+	games := make(map[string]bool)
+	for _, room := range []string{"room-a", "room-b", "room-c", "room-d", "room-e", "room-f"} {
+		moves := selfPlay(t, Config{Engine: EngineAlphaBeta}, game.Classic(), Easy, Seed(room))
+		games[fmt.Sprint(moves)] = true
+	}
+	if len(games) < 2 {
+		t.Errorf("easy bot played the same game for every seed")
+	}
+}
+
+func TestPerfectNeverLoses(t *testing.T) {
+	for _, side := range []game.Player{game.First, game.Second} {
+		g, err := game.New(game.Classic())
+		if err != nil {
+			t.Fatal(err)
+		}
+		bot := New(Config{Engine: EngineAlphaBeta}, game.Classic(), Perfect, 1, nil)
+		games := 0
+		againstAll(t, bot, side, g, &games)
+		t.Logf("side %d: %d games", side, games)
+	}
+}
+
+// againstAll перебирает все ответы соперника на ходы бота. Бот не должен
+// проигрывать ни одной партии и не должен ухудшать исход по таблице.
+func againstAll(t *testing.T, bot Engine, side game.Player, g *game.Game, games *int) {
+	t.Helper()
+	if r := g.Result(); r.Finished() {
+		*games++
+		if r.Outcome == game.Win && r.Winner != side {
+			t.Fatalf("perfect bot lost: %q", g.Board())
+		}
+		return
+	}
+
+	if g.Turn() != side {
+		for _, m := range g.LegalMoves() {
+			child := g.Clone()
+			child.Apply(m)
+			againstAll(t, bot, side, child, games)
+		}
+		return
+	}
+
+	before, _, ok := lookup(g)
+	if !ok {
+		t.Fatalf("position %q is missing from the table", g.Board())
+	}
+	m, err := bot.BestMove(context.Background(), g)
+	if err != nil {
+		t.Fatalf("BestMove(%q): %v", g.Board(), err)
+	}
+	child := g.Clone()
+	if _, err := child.Apply(m); err != nil {
+		t.Fatalf("BestMove(%q) = %+v: %v", g.Board(), m, err)
+	}
+
+	// Исход после хода с точки зрения бота — обратный исходу соперника
+	after := ValueDraw
+	if r := child.Result(); r.Outcome == game.Win {
+		after = ValueWin
+	} else if !r.Finished() {
+		s, _, _ := lookup(child)
+		switch s.Value {
+		case ValueWin:
+			after = ValueLoss
+		case ValueLoss:
+			after = ValueWin
+		}
+	}
+	if after != before.Value {
+		t.Fatalf("move %d in %q gives %s, table says %s", m.Position, g.Board(), after, before.Value)
+	}
+	againstAll(t, bot, side, child, games)
+}
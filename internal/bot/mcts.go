@@ -0,0 +1,214 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"tic_tac_toe/internal/game"
+	"time"
+)
+
+// Количество итераций, если не задан ни лимит итераций, ни лимит времени
+const defaultIterations = 10_000
+
+// MCTS — поиск по дереву Монте-Карло (UCT). Несколько горутин развивают
+// одно дерево: выбор узла и обратное распространение идут под блокировкой,
+// а случайные доигрывания — параллельно. Дерево сохраняется между ходами,
+// поэтому движок стоит держать отдельным для каждой комнаты.
+type MCTS struct {
+	Iterations  int           // Лимит итераций на ход, 0 — только по времени
+	Budget      time.Duration // Лимит времени на ход, 0 — только по итерациям
+	Workers     int           // Количество горутин, 0 — по числу процессоров
+	Exploration float64       // Константа UCT, 0 — √2
+	Seed        uint64
+
+	mu   sync.Mutex // Один поиск за раз: дерево переиспользуется между ходами
+	root *node
+}
+
+type node struct {
+	move     game.Move
+	mover    game.Player // Игрок, сделавший ход move
+	key      string      // Позиция после хода, по ней дерево находится на следующем ходу
+	parent   *node
+	children []*node
+	untried  []game.Move
+	visits   int
+	virtual  int     // Доигрывания, которые сейчас идут через узел
+	reward   float64 // Сумма результатов с точки зрения mover: победа — 1, ничья — 0.5
+}
+
+// BestMove выбирает самый посещаемый ход
+func (m *MCTS) BestMove(ctx context.Context, g *game.Game) (game.Move, error) {
+	moves, err := m.Analyse(ctx, g)
+	if err != nil {
+		return game.Move{}, err
+	}
+	return moves[0].Move, nil
+}
+
+// Analyse возвращает ходы, отсортированные по количеству посещений;
+// оно же служит оценкой хода
+func (m *MCTS) Analyse(ctx context.Context, g *game.Game) ([]Scored, error) {
+	if len(g.LegalMoves()) == 0 {
+		return nil, ErrNoMoves
+	}
+
+	if m.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.Budget)
+		defer cancel()
+	}
+	limit := int64(m.Iterations)
+	if limit == 0 && m.Budget == 0 {
+		limit = defaultIterations
+	}
+	workers := m.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	c := m.Exploration
+	if c == 0 {
+		c = math.Sqrt2
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := &tree{root: m.reuse(g), c: c}
+	var started atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		rnd := rand.New(rand.NewPCG(m.Seed, uint64(plies(g))<<16|uint64(w)))
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil && (limit == 0 || started.Add(1) <= limit) {
+				t.iterate(g, rnd)
+			}
+		}()
+	}
+	wg.Wait()
+	m.root = t.root
+
+	children := append([]*node(nil), t.root.children...)
+	if len(children) == 0 {
+		// Ни одной итерации не успели: отвечаем первым подходящим ходом
+		return []Scored{{Move: t.root.untried[0]}}, nil
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].visits > children[j].visits
+	})
+	scored := make([]Scored, len(children))
+	for i, n := range children {
+		scored[i] = Scored{Move: n.move, Score: n.visits}
+	}
+	return scored, nil
+}
+
+// reuse ищет текущую позицию среди ближайших потомков прошлого корня:
+// за время между поисками сделано не больше двух ходов — наш и соперника
+func (m *MCTS) reuse(g *game.Game) *node {
+	key := positionKey(g)
+	if m.root != nil {
+		level := []*node{m.root}
+		for depth := 0; depth <= 2; depth++ {
+			var next []*node
+			for _, n := range level {
+				if n.key == key {
+					n.parent = nil
+					return n
+				}
+				next = append(next, n.children...)
+			}
+			level = next
+		}
+	}
+	return &node{mover: g.Turn().Other(), key: key, untried: candidates(g)}
+}
+
+type tree struct {
+	mu   sync.Mutex
+	root *node
+	c    float64
+}
+
+// iterate проводит одну итерацию: выбор, расширение, доигрывание и обратное распространение
+func (t *tree) iterate(g *game.Game, rnd *rand.Rand) {
+	t.mu.Lock()
+	n, state := t.root, g.Clone()
+	for len(n.untried) == 0 && len(n.children) > 0 {
+		n = t.selectChild(n)
+		state.Apply(n.move)
+	}
+	if len(n.untried) > 0 {
+		i := rnd.IntN(len(n.untried))
+		move := n.untried[i]
+		n.untried[i] = n.untried[len(n.untried)-1]
+		n.untried = n.untried[:len(n.untried)-1]
+
+		state.Apply(move)
+		child := &node{move: move, mover: state.Turn().Other(), key: positionKey(state), parent: n}
+		if !state.Result().Finished() {
+			child.untried = candidates(state)
+		}
+		n.children = append(n.children, child)
+		n = child
+	}
+	// Виртуальная потеря уводит другие горутины с того же пути
+	for p := n; p != nil; p = p.parent {
+		p.virtual++
+	}
+	t.mu.Unlock()
+
+	result := rollout(state, rnd)
+
+	t.mu.Lock()
+	for p := n; p != nil; p = p.parent {
+		p.virtual--
+		p.visits++
+		p.reward += reward(result, p.mover)
+	}
+	t.mu.Unlock()
+}
+
+// selectChild выбирает потомка по формуле UCT
+func (t *tree) selectChild(n *node) *node {
+	parentVisits := math.Log(float64(n.visits + n.virtual + 1))
+	var best *node
+	bestValue := math.Inf(-1)
+	for _, child := range n.children {
+		visits := float64(child.visits + child.virtual)
+		value := math.Inf(1)
+		if visits > 0 {
+			value = child.reward/visits + t.c*math.Sqrt(parentVisits/visits)
+		}
+		if value > bestValue {
+			best, bestValue = child, value
+		}
+	}
+	return best
+}
+
+// rollout доигрывает партию случайными ходами
+func rollout(g *game.Game, rnd *rand.Rand) game.Result {
+	for !g.Result().Finished() {
+		moves := g.LegalMoves()
+		g.Apply(moves[rnd.IntN(len(moves))])
+	}
+	return g.Result()
+}
+
+func reward(r game.Result, p game.Player) float64 {
+	switch {
+	case r.Outcome == game.Draw:
+		return 0.5
+	case r.Winner == p:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package bot
+
+import "tic_tac_toe/internal/game"
+
+// Поле, начиная с которого перебираются только ходы рядом с занятыми клетками
+const sparseCells = 49
+
+// candidates возвращает ходы для перебора. На большом плоском поле далёкие
+// от всех фишек клетки почти никогда не бывают лучшими, поэтому смотрим
+// только клетки не дальше двух шагов от занятых.
+func candidates(g *game.Game) []game.Move {
+	moves := g.LegalMoves()
+	cfg := g.Config()
+	if cfg.Mode != game.Standard || cfg.Cells() < sparseCells {
+		return moves
+	}
+
+	board := g.Board()
+	near := func(pos int) bool {
+		row, col := cfg.Coords(pos)
+		for r := max(row-2, 0); r <= min(row+2, cfg.Height-1); r++ {
+			for c := max(col-2, 0); c <= min(col+2, cfg.Width-1); c++ {
+				if game.Mark(board[r*cfg.Width+c]) != game.Empty {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	filtered := make([]game.Move, 0, len(moves))
+	for _, m := range moves {
+		if near(m.Position) {
+			filtered = append(filtered, m)
+		}
+	}
+	if len(filtered) == 0 {
+		// Пустое поле: достаточно одного хода в центр
+		center := moves[0]
+		for _, m := range moves {
+			if centerDistance(cfg, m.Position) < centerDistance(cfg, center.Position) {
+				center = m
+			}
+		}
+		return []game.Move{center}
+	}
+	return filtered
+}
+
+// centerDistance возвращает удвоенное манхэттенское расстояние от клетки до центра поля
+func centerDistance(cfg game.Config, pos int) int {
+	depth := cfg.Cells() / (cfg.Width * cfg.Height)
+	layer, row, col := cfg.Coords3(pos)
+	return dist(2*layer, depth-1) + dist(2*row, cfg.Height-1) + dist(2*col, cfg.Width-1)
+}
+
+func dist(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
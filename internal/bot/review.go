@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"tic_tac_toe/internal/game"
+)
+
+// Review — разбор одного хода партии с точки зрения сделавшего его игрока
+type Review struct {
+	Move    game.Move
+	Player  game.Player
+	Before  Value     // Лучший исход, доступный до хода
+	After   Value     // Исход после сделанного хода
+	Best    game.Move // Лучший ход в позиции
+	Mistake bool      // Ход ухудшил исход: победу в ничью или поражение, ничью в поражение
+}
+
+// rank упорядочивает исходы; неизвестный исход не сравнивается
+var rank = map[Value]int{ValueLoss: 0, ValueDraw: 1, ValueWin: 2}
+
+// ReviewGame переигрывает партию с начала и оценивает каждый ход решателем.
+// Для каждой позиции решатель запускается один раз: оценка лучшего хода даёт
+// исход до хода, а оценка сыгранного — исход после.
+func ReviewGame(ctx context.Context, solver *AlphaBeta, cfg game.Config, moves []game.Move) ([]Review, error) {
+	g, err := game.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]Review, 0, len(moves))
+	for _, move := range moves {
+		evals, err := solver.Solve(ctx, g)
+		if err != nil {
+			return nil, err
+		}
+
+		r := Review{Move: move, Player: g.Turn(), Before: evals[0].Value, After: ValueUnknown, Best: evals[0].Move}
+		for _, e := range evals {
+			if e.Move.Position == move.Position && (move.Mark == game.Empty || e.Move.Mark == move.Mark) {
+				r.Move, r.After = e.Move, e.Value
+				break
+			}
+		}
+		before, okBefore := rank[r.Before]
+		after, okAfter := rank[r.After]
+		r.Mistake = okBefore && okAfter && after < before
+
+		if _, err := g.Apply(r.Move); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, nil
+}
@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"context"
+	"tic_tac_toe/internal/game"
+)
+
+// Value — теоретико-игровая оценка хода для сделавшего его игрока
+type Value string
+
+const (
+	ValueWin     Value = "win"
+	ValueDraw    Value = "draw"
+	ValueLoss    Value = "loss"
+	ValueUnknown Value = "unknown" // Перебор не успел дойти до конца
+)
+
+// Evaluation — оценка хода: исход при лучшей игре обеих сторон и сколько
+// полуходов (включая сам ход) остаётся до конца партии при победе или поражении
+type Evaluation struct {
+	Move  game.Move
+	Value Value
+	Plies int
+}
+
+// Solve оценивает каждый допустимый ход текущего игрока полным перебором.
+// На маленьких полях перебор доходит до конца партии, на больших за отведённое
+// время удаётся доказать лишь форсированные победы и поражения, а остальные
+// ходы получают ValueUnknown. Позиции классического поля 3x3 берутся из
+// готовой таблицы. Ходы отсортированы от лучшего к худшему.
+func (a *AlphaBeta) Solve(ctx context.Context, g *game.Game) ([]Evaluation, error) {
+	moves := g.LegalMoves()
+	if len(moves) == 0 {
+		return nil, ErrNoMoves
+	}
+	if evals, ok := tableEvaluations(g); ok {
+		return evals, nil
+	}
+
+	scored, complete := a.analyse(ctx, g, moves, true)
+	evals := make([]Evaluation, len(scored))
+	for i, sc := range scored {
+		e := Evaluation{Move: sc.Move, Value: ValueUnknown}
+		switch {
+		case sc.Score >= mateBound:
+			e.Value, e.Plies = ValueWin, winScore-sc.Score
+		case sc.Score <= -mateBound:
+			e.Value, e.Plies = ValueLoss, winScore+sc.Score
+		case complete:
+			e.Value = ValueDraw
+		}
+		evals[i] = e
+	}
+	return evals, nil
+}
@@ -0,0 +1,773 @@
+// Code generated by cmd/solve; DO NOT EDIT.
+
+package bot
+
+func init() {
+	solved = map[string]Solved{
+		"         ": {ValueDraw, 0, 0},
+		"        O": {ValueDraw, 0, 4},
+		"       O ": {ValueDraw, 0, 8},
+		"       OX": {ValueWin, 5, 5},
+		"       XO": {ValueDraw, 0, 5},
+		"      O X": {ValueWin, 5, 5},
+		"      OOX": {ValueWin, 5, 5},
+		"      OXO": {ValueDraw, 0, 4},
+		"     O OX": {ValueDraw, 0, 4},
+		"     O X ": {ValueWin, 5, 8},
+		"     O XO": {ValueLoss, 4, 2},
+		"     OO X": {ValueDraw, 0, 3},
+		"     OOX ": {ValueDraw, 0, 4},
+		"     OOXX": {ValueWin, 3, 4},
+		"     OX  ": {ValueWin, 5, 8},
+		"     OX O": {ValueLoss, 4, 2},
+		"     OXO ": {ValueWin, 5, 0},
+		"     OXOX": {ValueWin, 3, 4},
+		"     OXXO": {ValueLoss, 4, 2},
+		"     XO  ": {ValueWin, 5, 8},
+		"     XO O": {ValueLoss, 4, 7},
+		"     XOO ": {ValueWin, 5, 8},
+		"     XOOX": {ValueWin, 1, 2},
+		"     XOXO": {ValueWin, 3, 4},
+		"     XXOO": {ValueWin, 3, 3},
+		"    O    ": {ValueDraw, 0, 0},
+		"    O   X": {ValueDraw, 0, 7},
+		"    O  OX": {ValueDraw, 0, 1},
+		"    O  X ": {ValueDraw, 0, 8},
+		"    O  XO": {ValueLoss, 4, 0},
+		"    O O X": {ValueDraw, 0, 2},
+		"    O OXX": {ValueDraw, 0, 2},
+		"    O XOX": {ValueDraw, 0, 1},
+		"    OO X ": {ValueLoss, 4, 3},
+		"    OO XX": {ValueWin, 1, 6},
+		"    OOOXX": {ValueLoss, 2, 3},
+		"    OOX  ": {ValueDraw, 0, 3},
+		"    OOX X": {ValueWin, 1, 7},
+		"    OOXOX": {ValueLoss, 2, 3},
+		"    OOXX ": {ValueWin, 1, 8},
+		"    OOXXO": {ValueLoss, 2, 3},
+		"    OX XO": {ValueDraw, 0, 0},
+		"    OXO  ": {ValueLoss, 4, 2},
+		"    OXO X": {ValueWin, 1, 2},
+		"    OXOOX": {ValueWin, 1, 2},
+		"    OXOX ": {ValueDraw, 0, 2},
+		"    OXOXO": {ValueLoss, 2, 3},
+		"    OXX O": {ValueDraw, 0, 0},
+		"    OXXO ": {ValueDraw, 0, 1},
+		"    OXXOO": {ValueLoss, 2, 2},
+		"    X   O": {ValueDraw, 0, 7},
+		"    X  O ": {ValueWin, 5, 8},
+		"    X  OO": {ValueDraw, 0, 6},
+		"    X O O": {ValueDraw, 0, 7},
+		"    X OOX": {ValueWin, 1, 0},
+		"    X OXO": {ValueWin, 1, 1},
+		"    XO O ": {ValueDraw, 0, 8},
+		"    XO OX": {ValueWin, 1, 0},
+		"    XO XO": {ValueWin, 1, 1},
+		"    XOO  ": {ValueDraw, 0, 7},
+		"    XOO X": {ValueWin, 1, 0},
+		"    XOOOX": {ValueWin, 1, 0},
+		"    XOOX ": {ValueWin, 1, 1},
+		"    XOOXO": {ValueWin, 1, 1},
+		"    XOX O": {ValueWin, 1, 2},
+		"    XOXO ": {ValueWin, 1, 2},
+		"    XOXOO": {ValueWin, 1, 2},
+		"    XXO O": {ValueWin, 1, 3},
+		"    XXOO ": {ValueWin, 1, 3},
+		"    XXOOO": {ValueLoss, 0, -1},
+		"   O O  X": {ValueWin, 5, 4},
+		"   O O X ": {ValueWin, 5, 4},
+		"   O O XX": {ValueWin, 1, 6},
+		"   O OOXX": {ValueLoss, 2, 4},
+		"   O OX X": {ValueWin, 1, 7},
+		"   O OXOX": {ValueWin, 3, 4},
+		"   O X   ": {ValueDraw, 0, 2},
+		"   O X  O": {ValueDraw, 0, 6},
+		"   O X O ": {ValueDraw, 0, 6},
+		"   O X OX": {ValueWin, 1, 2},
+		"   O X XO": {ValueDraw, 0, 6},
+		"   O XO  ": {ValueDraw, 0, 0},
+		"   O XO X": {ValueWin, 1, 2},
+		"   O XOOX": {ValueWin, 1, 2},
+		"   O XOX ": {ValueWin, 5, 0},
+		"   O XOXO": {ValueDraw, 0, 0},
+		"   O XX O": {ValueDraw, 0, 0},
+		"   O XXO ": {ValueWin, 3, 2},
+		"   O XXOO": {ValueDraw, 0, 4},
+		"   OOO XX": {ValueLoss, 0, -1},
+		"   OOOX X": {ValueLoss, 0, -1},
+		"   OOX   ": {ValueDraw, 0, 6},
+		"   OOX  X": {ValueWin, 1, 2},
+		"   OOX OX": {ValueWin, 1, 2},
+		"   OOX X ": {ValueWin, 3, 8},
+		"   OOX XO": {ValueDraw, 0, 0},
+		"   OOXO X": {ValueWin, 1, 2},
+		"   OOXOX ": {ValueLoss, 2, 8},
+		"   OOXOXX": {ValueWin, 1, 2},
+		"   OOXX  ": {ValueWin, 3, 8},
+		"   OOXX O": {ValueDraw, 0, 0},
+		"   OOXXO ": {ValueDraw, 0, 1},
+		"   OOXXOX": {ValueWin, 1, 2},
+		"   OOXXXO": {ValueDraw, 0, 0},
+		"   OXO   ": {ValueWin, 5, 6},
+		"   OXO  X": {ValueWin, 1, 0},
+		"   OXO OX": {ValueWin, 1, 0},
+		"   OXO X ": {ValueWin, 1, 1},
+		"   OXO XO": {ValueWin, 1, 1},
+		"   OXOO X": {ValueWin, 1, 0},
+		"   OXOOXX": {ValueWin, 1, 1},
+		"   OXOXOX": {ValueWin, 1, 2},
+		"   OXX  O": {ValueDraw, 0, 7},
+		"   OXX O ": {ValueWin, 3, 8},
+		"   OXX OO": {ValueDraw, 0, 6},
+		"   OXXO  ": {ValueDraw, 0, 0},
+		"   OXXO O": {ValueLoss, 2, 2},
+		"   OXXOO ": {ValueLoss, 2, 8},
+		"   OXXOOX": {ValueWin, 1, 2},
+		"   OXXOXO": {ValueWin, 1, 1},
+		"   OXXXOO": {ValueWin, 1, 2},
+		"   X X OO": {ValueWin, 1, 4},
+		"   X XO O": {ValueWin, 1, 4},
+		"   X XOOO": {ValueLoss, 0, -1},
+		"   XOX  O": {ValueLoss, 4, 0},
+		"   XOX O ": {ValueLoss, 4, 1},
+		"   XOX OO": {ValueLoss, 2, 2},
+		"   XOXO O": {ValueLoss, 2, 0},
+		"   XOXOOX": {ValueWin, 1, 2},
+		"   XOXOXO": {ValueLoss, 2, 2},
+		"  O   O X": {ValueLoss, 4, 4},
+		"  O   OX ": {ValueDraw, 0, 4},
+		"  O   OXX": {ValueWin, 3, 4},
+		"  O   X  ": {ValueWin, 5, 0},
+		"  O   X O": {ValueLoss, 4, 5},
+		"  O   XO ": {ValueDraw, 0, 1},
+		"  O   XOX": {ValueWin, 3, 0},
+		"  O   XXO": {ValueLoss, 4, 5},
+		"  O  OOXX": {ValueWin, 3, 4},
+		"  O  OX  ": {ValueWin, 5, 8},
+		"  O  OX X": {ValueWin, 1, 7},
+		"  O  OXOX": {ValueWin, 3, 0},
+		"  O  OXX ": {ValueWin, 1, 8},
+		"  O  OXXO": {ValueLoss, 0, -1},
+		"  O  XOX ": {ValueWin, 3, 4},
+		"  O  XOXO": {ValueWin, 3, 4},
+		"  O  XX O": {ValueWin, 3, 3},
+		"  O  XXO ": {ValueWin, 3, 3},
+		"  O  XXOO": {ValueWin, 3, 3},
+		"  O O OXX": {ValueLoss, 0, -1},
+		"  O O X  ": {ValueDraw, 0, 8},
+		"  O O X X": {ValueWin, 1, 7},
+		"  O O XOX": {ValueDraw, 0, 1},
+		"  O O XX ": {ValueWin, 1, 8},
+		"  O O XXO": {ValueLoss, 2, 5},
+		"  O OOX X": {ValueWin, 1, 7},
+		"  O OOXX ": {ValueWin, 1, 8},
+		"  O OXOX ": {ValueLoss, 0, -1},
+		"  O OXOXX": {ValueLoss, 0, -1},
+		"  O OXX  ": {ValueDraw, 0, 7},
+		"  O OXX O": {ValueDraw, 0, 0},
+		"  O OXXO ": {ValueDraw, 0, 1},
+		"  O OXXOX": {ValueDraw, 0, 1},
+		"  O OXXXO": {ValueDraw, 0, 0},
+		"  O X O  ": {ValueDraw, 0, 3},
+		"  O X O X": {ValueWin, 1, 0},
+		"  O X OOX": {ValueWin, 1, 0},
+		"  O X OX ": {ValueWin, 1, 1},
+		"  O X OXO": {ValueWin, 1, 1},
+		"  O X X O": {ValueDraw, 0, 5},
+		"  O X XO ": {ValueWin, 3, 3},
+		"  O X XOO": {ValueDraw, 0, 5},
+		"  O XOOX ": {ValueWin, 1, 1},
+		"  O XOOXX": {ValueWin, 1, 1},
+		"  O XOX  ": {ValueWin, 3, 8},
+		"  O XOX O": {ValueLoss, 0, -1},
+		"  O XOXO ": {ValueDraw, 0, 8},
+		"  O XOXOX": {ValueWin, 1, 0},
+		"  O XOXXO": {ValueLoss, 0, -1},
+		"  O XXOXO": {ValueWin, 1, 3},
+		"  O XXXOO": {ValueWin, 1, 3},
+		"  OO    X": {ValueDraw, 0, 6},
+		"  OO   X ": {ValueDraw, 0, 4},
+		"  OO   XX": {ValueWin, 1, 6},
+		"  OO  OXX": {ValueLoss, 2, 4},
+		"  OO  X X": {ValueWin, 1, 7},
+		"  OO  XOX": {ValueDraw, 0, 1},
+		"  OO  XX ": {ValueWin, 1, 8},
+		"  OO  XXO": {ValueLoss, 4, 5},
+		"  OO O XX": {ValueWin, 1, 6},
+		"  OO OX X": {ValueWin, 1, 7},
+		"  OO OXX ": {ValueWin, 1, 8},
+		"  OO X  X": {ValueDraw, 0, 6},
+		"  OO X OX": {ValueLoss, 4, 1},
+		"  OO X X ": {ValueDraw, 0, 1},
+		"  OO X XO": {ValueDraw, 0, 4},
+		"  OO XO X": {ValueLoss, 2, 1},
+		"  OO XOX ": {ValueLoss, 2, 8},
+		"  OO XOXX": {ValueLoss, 2, 4},
+		"  OO XX  ": {ValueDraw, 0, 0},
+		"  OO XX O": {ValueDraw, 0, 4},
+		"  OO XXO ": {ValueDraw, 0, 1},
+		"  OO XXOX": {ValueDraw, 0, 4},
+		"  OO XXXO": {ValueDraw, 0, 0},
+		"  OOO  XX": {ValueWin, 1, 6},
+		"  OOO X X": {ValueWin, 1, 7},
+		"  OOO XX ": {ValueWin, 1, 8},
+		"  OOOX  X": {ValueDraw, 0, 6},
+		"  OOOX X ": {ValueDraw, 0, 6},
+		"  OOOX XX": {ValueWin, 1, 6},
+		"  OOOXOXX": {ValueLoss, 0, -1},
+		"  OOOXX  ": {ValueDraw, 0, 8},
+		"  OOOXX X": {ValueWin, 1, 7},
+		"  OOOXXOX": {ValueDraw, 0, 1},
+		"  OOOXXX ": {ValueWin, 1, 8},
+		"  OOOXXXO": {ValueDraw, 0, 0},
+		"  OOX   X": {ValueWin, 1, 0},
+		"  OOX  OX": {ValueWin, 1, 0},
+		"  OOX  X ": {ValueWin, 1, 1},
+		"  OOX  XO": {ValueWin, 1, 1},
+		"  OOX O X": {ValueWin, 1, 0},
+		"  OOX OX ": {ValueWin, 1, 1},
+		"  OOX OXX": {ValueWin, 1, 1},
+		"  OOX X O": {ValueDraw, 0, 5},
+		"  OOX XO ": {ValueDraw, 0, 5},
+		"  OOX XOX": {ValueWin, 1, 0},
+		"  OOX XXO": {ValueWin, 1, 1},
+		"  OOXO  X": {ValueWin, 1, 0},
+		"  OOXO X ": {ValueWin, 1, 1},
+		"  OOXO XX": {ValueWin, 1, 1},
+		"  OOXOOXX": {ValueWin, 1, 0},
+		"  OOXOX  ": {ValueWin, 3, 8},
+		"  OOXOX X": {ValueWin, 1, 7},
+		"  OOXOXOX": {ValueWin, 1, 0},
+		"  OOXOXX ": {ValueWin, 1, 1},
+		"  OOXOXXO": {ValueLoss, 0, -1},
+		"  OOXX  O": {ValueDraw, 0, 1},
+		"  OOXX O ": {ValueDraw, 0, 0},
+		"  OOXX OX": {ValueWin, 1, 0},
+		"  OOXX XO": {ValueWin, 1, 1},
+		"  OOXXO  ": {ValueDraw, 0, 0},
+		"  OOXXO X": {ValueWin, 1, 0},
+		"  OOXXOOX": {ValueWin, 1, 0},
+		"  OOXXOX ": {ValueWin, 1, 1},
+		"  OOXXOXO": {ValueWin, 1, 1},
+		"  OOXXX O": {ValueDraw, 0, 0},
+		"  OOXXXO ": {ValueDraw, 0, 8},
+		"  OOXXXOO": {ValueDraw, 0, 1},
+		"  OX    O": {ValueLoss, 4, 5},
+		"  OX   OX": {ValueWin, 3, 4},
+		"  OX   XO": {ValueLoss, 4, 5},
+		"  OX  O X": {ValueWin, 3, 4},
+		"  OX  OOX": {ValueWin, 3, 4},
+		"  OX  OX ": {ValueWin, 3, 4},
+		"  OX  OXO": {ValueLoss, 2, 4},
+		"  OX  X O": {ValueWin, 1, 0},
+		"  OX  XOO": {ValueWin, 1, 0},
+		"  OX O  X": {ValueWin, 3, 6},
+		"  OX O OX": {ValueWin, 3, 0},
+		"  OX O X ": {ValueWin, 5, 8},
+		"  OX O XO": {ValueLoss, 0, -1},
+		"  OX OO X": {ValueDraw, 0, 4},
+		"  OX OOX ": {ValueLoss, 2, 8},
+		"  OX OOXX": {ValueWin, 3, 4},
+		"  OX OX  ": {ValueWin, 1, 0},
+		"  OX OX O": {ValueLoss, 0, -1},
+		"  OX OXO ": {ValueWin, 1, 0},
+		"  OX OXOX": {ValueWin, 1, 0},
+		"  OX OXXO": {ValueLoss, 0, -1},
+		"  OX X  O": {ValueWin, 1, 4},
+		"  OX X O ": {ValueWin, 1, 4},
+		"  OX X OO": {ValueWin, 1, 4},
+		"  OX XO  ": {ValueWin, 1, 4},
+		"  OX XO O": {ValueWin, 1, 4},
+		"  OX XOO ": {ValueWin, 1, 4},
+		"  OX XOOX": {ValueWin, 1, 4},
+		"  OX XOXO": {ValueWin, 1, 4},
+		"  OX XXOO": {ValueWin, 1, 4},
+		"  OXO   X": {ValueWin, 3, 6},
+		"  OXO  OX": {ValueLoss, 2, 5},
+		"  OXO  X ": {ValueWin, 3, 6},
+		"  OXO  XO": {ValueLoss, 2, 6},
+		"  OXO O X": {ValueLoss, 0, -1},
+		"  OXO OX ": {ValueLoss, 0, -1},
+		"  OXO OXX": {ValueLoss, 0, -1},
+		"  OXO X O": {ValueWin, 1, 0},
+		"  OXO XOX": {ValueWin, 1, 0},
+		"  OXO XXO": {ValueWin, 1, 0},
+		"  OXOO  X": {ValueWin, 3, 6},
+		"  OXOO X ": {ValueLoss, 2, 8},
+		"  OXOO XX": {ValueWin, 1, 6},
+		"  OXOOOXX": {ValueLoss, 0, -1},
+		"  OXOOX  ": {ValueWin, 1, 0},
+		"  OXOOX X": {ValueWin, 1, 7},
+		"  OXOOXOX": {ValueWin, 1, 0},
+		"  OXOOXX ": {ValueWin, 1, 8},
+		"  OXOOXXO": {ValueLoss, 0, -1},
+		"  OXOX  O": {ValueLoss, 2, 1},
+		"  OXOX O ": {ValueLoss, 2, 8},
+		"  OXOX OX": {ValueLoss, 2, 6},
+		"  OXOX XO": {ValueLoss, 2, 1},
+		"  OXOXO  ": {ValueLoss, 0, -1},
+		"  OXOXO X": {ValueLoss, 0, -1},
+		"  OXOXOOX": {ValueLoss, 0, -1},
+		"  OXOXOX ": {ValueLoss, 0, -1},
+		"  OXOXOXO": {ValueLoss, 0, -1},
+		"  OXOXX O": {ValueWin, 1, 0},
+		"  OXOXXO ": {ValueWin, 1, 0},
+		"  OXOXXOO": {ValueWin, 1, 0},
+		"  OXX   O": {ValueWin, 1, 5},
+		"  OXX  OO": {ValueWin, 1, 5},
+		"  OXX O O": {ValueWin, 1, 5},
+		"  OXX OOX": {ValueWin, 1, 5},
+		"  OXX OXO": {ValueWin, 1, 5},
+		"  OXX XOO": {ValueWin, 1, 5},
+		"  OXXO  O": {ValueLoss, 0, -1},
+		"  OXXO O ": {ValueDraw, 0, 8},
+		"  OXXO OX": {ValueWin, 1, 0},
+		"  OXXO XO": {ValueLoss, 0, -1},
+		"  OXXOO X": {ValueWin, 1, 0},
+		"  OXXOOOX": {ValueWin, 1, 0},
+		"  OXXOOX ": {ValueWin, 1, 1},
+		"  OXXOOXO": {ValueLoss, 0, -1},
+		"  OXXOX O": {ValueLoss, 0, -1},
+		"  OXXOXO ": {ValueWin, 1, 0},
+		"  OXXOXOO": {ValueLoss, 0, -1},
+		"  X   XOO": {ValueWin, 1, 4},
+		"  X  OXO ": {ValueWin, 1, 4},
+		"  X  OXOO": {ValueWin, 1, 4},
+		"  X O X O": {ValueWin, 3, 0},
+		"  X O XO ": {ValueDraw, 0, 1},
+		"  X O XOO": {ValueLoss, 2, 5},
+		"  X OOXO ": {ValueLoss, 2, 8},
+		"  X OOXOX": {ValueLoss, 2, 3},
+		"  X OOXXO": {ValueLoss, 2, 1},
+		"  XO   O ": {ValueWin, 5, 8},
+		"  XO   OX": {ValueWin, 1, 5},
+		"  XO   XO": {ValueWin, 3, 1},
+		"  XO  O X": {ValueWin, 1, 5},
+		"  XO  OOX": {ValueWin, 1, 5},
+		"  XO  OX ": {ValueWin, 5, 0},
+		"  XO  OXO": {ValueDraw, 0, 0},
+		"  XO  X O": {ValueWin, 1, 4},
+		"  XO  XO ": {ValueWin, 1, 4},
+		"  XO  XOO": {ValueWin, 1, 4},
+		"  XO O  X": {ValueWin, 3, 4},
+		"  XO O OX": {ValueWin, 3, 4},
+		"  XO O X ": {ValueWin, 3, 4},
+		"  XO O XO": {ValueWin, 3, 4},
+		"  XO OO X": {ValueLoss, 2, 7},
+		"  XO OOX ": {ValueLoss, 2, 8},
+		"  XO OOXX": {ValueLoss, 2, 4},
+		"  XO OX  ": {ValueWin, 1, 4},
+		"  XO OX O": {ValueWin, 1, 4},
+		"  XO OXO ": {ValueWin, 1, 4},
+		"  XO OXOX": {ValueWin, 1, 4},
+		"  XO OXXO": {ValueWin, 1, 4},
+		"  XO X O ": {ValueWin, 1, 8},
+		"  XO X OO": {ValueLoss, 4, 6},
+		"  XO XO O": {ValueLoss, 2, 7},
+		"  XO XOO ": {ValueWin, 1, 8},
+		"  XO XOXO": {ValueDraw, 0, 0},
+		"  XO XXOO": {ValueWin, 1, 4},
+		"  XOO   X": {ValueWin, 1, 5},
+		"  XOO  OX": {ValueWin, 1, 5},
+		"  XOO  X ": {ValueDraw, 0, 5},
+		"  XOO  XO": {ValueLoss, 2, 6},
+		"  XOO O X": {ValueWin, 1, 5},
+		"  XOO OX ": {ValueLoss, 2, 8},
+		"  XOO OXX": {ValueWin, 1, 5},
+		"  XOO X O": {ValueLoss, 2, 7},
+		"  XOO XO ": {ValueLoss, 2, 0},
+		"  XOO XOX": {ValueWin, 1, 5},
+		"  XOO XXO": {ValueLoss, 2, 0},
+		"  XOOO  X": {ValueLoss, 0, -1},
+		"  XOOO X ": {ValueLoss, 0, -1},
+		"  XOOO XX": {ValueLoss, 0, -1},
+		"  XOOOOXX": {ValueLoss, 0, -1},
+		"  XOOOX  ": {ValueLoss, 0, -1},
+		"  XOOOX X": {ValueLoss, 0, -1},
+		"  XOOOXOX": {ValueLoss, 0, -1},
+		"  XOOOXX ": {ValueLoss, 0, -1},
+		"  XOOOXXO": {ValueLoss, 0, -1},
+		"  XOOX O ": {ValueWin, 1, 8},
+		"  XOOX XO": {ValueDraw, 0, 0},
+		"  XOOXOX ": {ValueWin, 1, 8},
+		"  XOOXOXO": {ValueDraw, 0, 0},
+		"  XOOXX O": {ValueDraw, 0, 0},
+		"  XOOXXO ": {ValueWin, 1, 8},
+		"  XOOXXOO": {ValueLoss, 2, 1},
+		"  XOX  O ": {ValueWin, 1, 6},
+		"  XOX  OO": {ValueWin, 1, 6},
+		"  XOX O O": {ValueLoss, 2, 5},
+		"  XOX OO ": {ValueLoss, 2, 0},
+		"  XOX OOX": {ValueWin, 1, 5},
+		"  XOX OXO": {ValueWin, 1, 1},
+		"  XOXO O ": {ValueWin, 1, 6},
+		"  XOXO OX": {ValueWin, 1, 6},
+		"  XOXO XO": {ValueWin, 1, 1},
+		"  XOXOO X": {ValueWin, 1, 0},
+		"  XOXOOOX": {ValueWin, 1, 0},
+		"  XOXOOX ": {ValueWin, 1, 1},
+		"  XOXOOXO": {ValueWin, 1, 1},
+		"  XOXX OO": {ValueWin, 1, 6},
+		"  XOXXO O": {ValueLoss, 2, 1},
+		"  XOXXOO ": {ValueWin, 1, 8},
+		"  XOXXOOO": {ValueLoss, 0, -1},
+		"  XX   OO": {ValueWin, 3, 6},
+		"  XX  O O": {ValueDraw, 0, 7},
+		"  XX  OOO": {ValueLoss, 0, -1},
+		"  XX O O ": {ValueWin, 3, 0},
+		"  XX O OO": {ValueWin, 3, 6},
+		"  XX OO O": {ValueDraw, 0, 7},
+		"  XX OOO ": {ValueDraw, 0, 8},
+		"  XX OOOX": {ValueWin, 3, 0},
+		"  XX OOXO": {ValueWin, 3, 1},
+		"  XX OXOO": {ValueWin, 1, 4},
+		"  XX XOOO": {ValueLoss, 0, -1},
+		"  XXO  OO": {ValueLoss, 2, 5},
+		"  XXO O O": {ValueLoss, 2, 0},
+		"  XXO OOX": {ValueWin, 1, 5},
+		"  XXO OXO": {ValueDraw, 0, 0},
+		"  XXO XOO": {ValueWin, 1, 0},
+		"  XXOO O ": {ValueDraw, 0, 1},
+		"  XXOO OX": {ValueDraw, 0, 1},
+		"  XXOO XO": {ValueWin, 3, 0},
+		"  XXOOO X": {ValueDraw, 0, 7},
+		"  XXOOOOX": {ValueDraw, 0, 1},
+		"  XXOOOX ": {ValueDraw, 0, 8},
+		"  XXOOOXO": {ValueDraw, 0, 0},
+		"  XXOOX O": {ValueWin, 1, 0},
+		"  XXOOXO ": {ValueWin, 1, 0},
+		"  XXOOXOO": {ValueWin, 1, 0},
+		"  XXOX OO": {ValueLoss, 2, 1},
+		"  XXOXO O": {ValueLoss, 2, 1},
+		"  XXOXOO ": {ValueWin, 1, 8},
+		"  XXOXOOO": {ValueLoss, 0, -1},
+		"  XXX OOO": {ValueLoss, 0, -1},
+		"  XXXO OO": {ValueWin, 1, 6},
+		"  XXXOO O": {ValueDraw, 0, 7},
+		"  XXXOOO ": {ValueDraw, 0, 8},
+		"  XXXOOOO": {ValueLoss, 0, -1},
+		" O O O XX": {ValueWin, 1, 6},
+		" O O OX X": {ValueWin, 1, 7},
+		" O O X X ": {ValueWin, 3, 8},
+		" O O X XO": {ValueDraw, 0, 0},
+		" O O XO X": {ValueWin, 1, 2},
+		" O O XOX ": {ValueDraw, 0, 0},
+		" O O XOXX": {ValueWin, 1, 2},
+		" O O XX O": {ValueDraw, 0, 4},
+		" O O XXO ": {ValueDraw, 0, 4},
+		" O O XXOX": {ValueWin, 1, 2},
+		" O O XXXO": {ValueDraw, 0, 0},
+		" O OOX X ": {ValueWin, 3, 8},
+		" O OOX XX": {ValueWin, 1, 6},
+		" O OOXOXX": {ValueWin, 1, 2},
+		" O OOXX X": {ValueWin, 1, 7},
+		" O OOXXOX": {ValueLoss, 0, -1},
+		" O OOXXX ": {ValueWin, 1, 8},
+		" O OOXXXO": {ValueDraw, 0, 0},
+		" O OXO X ": {ValueWin, 3, 6},
+		" O OXO XX": {ValueWin, 1, 6},
+		" O OXOOXX": {ValueWin, 1, 0},
+		" O OXOX X": {ValueWin, 1, 0},
+		" O OXOXOX": {ValueWin, 1, 2},
+		" O OXX XO": {ValueDraw, 0, 6},
+		" O OXXO X": {ValueWin, 1, 2},
+		" O OXXOOX": {ValueWin, 1, 0},
+		" O OXXOX ": {ValueDraw, 0, 0},
+		" O OXXOXO": {ValueDraw, 0, 0},
+		" O OXXX O": {ValueWin, 1, 2},
+		" O OXXXO ": {ValueWin, 1, 2},
+		" O OXXXOO": {ValueWin, 1, 2},
+		" O X X O ": {ValueWin, 1, 4},
+		" O X X OO": {ValueWin, 1, 4},
+		" O X XO O": {ValueWin, 1, 4},
+		" O X XOOX": {ValueWin, 1, 4},
+		" O X XOXO": {ValueWin, 1, 4},
+		" O XOX O ": {ValueLoss, 0, -1},
+		" O XOX OX": {ValueLoss, 0, -1},
+		" O XOX XO": {ValueDraw, 0, 0},
+		" O XOXO X": {ValueWin, 1, 2},
+		" O XOXOOX": {ValueLoss, 0, -1},
+		" O XOXOXO": {ValueLoss, 2, 2},
+		" OOO  X X": {ValueWin, 1, 7},
+		" OOO XOXX": {ValueLoss, 2, 4},
+		" OOO XX X": {ValueWin, 1, 7},
+		" OOO XXOX": {ValueLoss, 2, 4},
+		" OOO XXX ": {ValueWin, 1, 8},
+		" OOO XXXO": {ValueDraw, 0, 0},
+		" OOOOXX X": {ValueWin, 1, 7},
+		" OOOOXXX ": {ValueWin, 1, 8},
+		" OOOX OXX": {ValueWin, 1, 0},
+		" OOOX X X": {ValueWin, 1, 0},
+		" OOOX XOX": {ValueWin, 1, 0},
+		" OOOX XXO": {ValueLoss, 2, 5},
+		" OOOXOX X": {ValueWin, 1, 7},
+		" OOOXOXX ": {ValueWin, 1, 8},
+		" OOOXXOX ": {ValueDraw, 0, 0},
+		" OOOXXOXX": {ValueWin, 1, 0},
+		" OOOXXX O": {ValueDraw, 0, 0},
+		" OOOXXXO ": {ValueDraw, 0, 0},
+		" OOOXXXOX": {ValueWin, 1, 0},
+		" OOOXXXXO": {ValueDraw, 0, 0},
+		" OOX   OX": {ValueLoss, 2, 5},
+		" OOX   XO": {ValueLoss, 2, 6},
+		" OOX  O X": {ValueLoss, 2, 0},
+		" OOX  OXX": {ValueLoss, 2, 5},
+		" OOX  X O": {ValueWin, 1, 0},
+		" OOX  XOX": {ValueWin, 1, 0},
+		" OOX  XXO": {ValueWin, 1, 0},
+		" OOX O X ": {ValueLoss, 2, 8},
+		" OOX O XX": {ValueWin, 1, 6},
+		" OOX OOXX": {ValueLoss, 2, 4},
+		" OOX OX X": {ValueWin, 1, 7},
+		" OOX OXOX": {ValueWin, 1, 0},
+		" OOX OXX ": {ValueWin, 1, 0},
+		" OOX OXXO": {ValueLoss, 0, -1},
+		" OOX X OX": {ValueWin, 1, 4},
+		" OOX X XO": {ValueWin, 1, 4},
+		" OOX XO X": {ValueWin, 1, 4},
+		" OOX XOOX": {ValueWin, 1, 4},
+		" OOX XOX ": {ValueWin, 1, 4},
+		" OOX XOXO": {ValueWin, 1, 4},
+		" OOX XX O": {ValueWin, 1, 4},
+		" OOX XXO ": {ValueWin, 1, 4},
+		" OOX XXOO": {ValueWin, 1, 4},
+		" OOXO  XX": {ValueWin, 1, 6},
+		" OOXO OXX": {ValueLoss, 0, -1},
+		" OOXO X X": {ValueWin, 1, 7},
+		" OOXO XOX": {ValueLoss, 0, -1},
+		" OOXO XXO": {ValueWin, 1, 0},
+		" OOXOO XX": {ValueWin, 1, 6},
+		" OOXOOX X": {ValueWin, 1, 7},
+		" OOXOOXX ": {ValueWin, 1, 8},
+		" OOXOX OX": {ValueLoss, 0, -1},
+		" OOXOX X ": {ValueLoss, 2, 8},
+		" OOXOX XO": {ValueLoss, 2, 6},
+		" OOXOXO X": {ValueLoss, 0, -1},
+		" OOXOXOX ": {ValueLoss, 0, -1},
+		" OOXOXOXX": {ValueLoss, 0, -1},
+		" OOXOXX O": {ValueWin, 1, 0},
+		" OOXOXXO ": {ValueLoss, 0, -1},
+		" OOXOXXOX": {ValueLoss, 0, -1},
+		" OOXOXXXO": {ValueWin, 1, 0},
+		" OOXX  OX": {ValueWin, 1, 5},
+		" OOXX  XO": {ValueWin, 1, 5},
+		" OOXX O X": {ValueWin, 1, 5},
+		" OOXX OOX": {ValueWin, 1, 0},
+		" OOXX OXO": {ValueWin, 1, 5},
+		" OOXX X O": {ValueWin, 1, 5},
+		" OOXX XOO": {ValueWin, 1, 5},
+		" OOXXO OX": {ValueWin, 1, 0},
+		" OOXXO X ": {ValueLoss, 2, 8},
+		" OOXXO XO": {ValueLoss, 0, -1},
+		" OOXXOO X": {ValueWin, 1, 0},
+		" OOXXOOX ": {ValueLoss, 2, 0},
+		" OOXXOOXX": {ValueWin, 1, 0},
+		" OOXXOX O": {ValueLoss, 0, -1},
+		" OOXXOXOX": {ValueWin, 1, 0},
+		" OOXXOXXO": {ValueLoss, 0, -1},
+		" OXO  X O": {ValueWin, 1, 4},
+		" OXO  XOX": {ValueWin, 1, 4},
+		" OXO  XXO": {ValueWin, 1, 4},
+		" OXO OXOX": {ValueWin, 1, 4},
+		" OXO OXX ": {ValueWin, 1, 4},
+		" OXO OXXO": {ValueWin, 1, 4},
+		" OXOO X X": {ValueWin, 1, 7},
+		" OXOO XOX": {ValueLoss, 0, -1},
+		" OXOO XXO": {ValueLoss, 2, 5},
+		" OXOOOXX ": {ValueLoss, 0, -1},
+		" OXOOXXXO": {ValueDraw, 0, 0},
+		" OXX  O O": {ValueDraw, 0, 7},
+		" OXX  OOX": {ValueWin, 1, 5},
+		" OXX  OXO": {ValueDraw, 0, 0},
+		" OXX  XOO": {ValueWin, 1, 4},
+		" OXX O OX": {ValueWin, 3, 4},
+		" OXX O XO": {ValueWin, 3, 6},
+		" OXX OO X": {ValueDraw, 0, 4},
+		" OXX OOOX": {ValueDraw, 0, 4},
+		" OXX OOX ": {ValueDraw, 0, 8},
+		" OXX OOXO": {ValueDraw, 0, 4},
+		" OXX OX O": {ValueWin, 1, 0},
+		" OXX OXOO": {ValueWin, 1, 4},
+		" OXX XO O": {ValueWin, 1, 4},
+		" OXX XOOO": {ValueLoss, 0, -1},
+		" OXXO  OX": {ValueLoss, 0, -1},
+		" OXXO  XO": {ValueDraw, 0, 0},
+		" OXXO O X": {ValueWin, 1, 5},
+		" OXXO OOX": {ValueLoss, 0, -1},
+		" OXXO OXO": {ValueDraw, 0, 0},
+		" OXXO X O": {ValueWin, 1, 0},
+		" OXXO XOO": {ValueLoss, 0, -1},
+		" OXXOO OX": {ValueLoss, 0, -1},
+		" OXXOO X ": {ValueWin, 3, 6},
+		" OXXOO XO": {ValueDraw, 0, 0},
+		" OXXOOO X": {ValueDraw, 0, 7},
+		" OXXOOOX ": {ValueDraw, 0, 0},
+		" OXXOOOXX": {ValueDraw, 0, 0},
+		" OXXOOX O": {ValueWin, 1, 0},
+		" OXXOOXOX": {ValueLoss, 0, -1},
+		" OXXOOXXO": {ValueWin, 1, 0},
+		" OXXOXO O": {ValueLoss, 2, 7},
+		" OXXOXOXO": {ValueDraw, 0, 0},
+		" OXXOXXOO": {ValueLoss, 0, -1},
+		" OXXX O O": {ValueWin, 1, 5},
+		" OXXX OOO": {ValueLoss, 0, -1},
+		" OXXXOO O": {ValueDraw, 0, 7},
+		" OXXXOOOX": {ValueWin, 1, 0},
+		" OXXXOOXO": {ValueDraw, 0, 0},
+		" X X XOOO": {ValueLoss, 0, -1},
+		" X XOXO O": {ValueLoss, 2, 0},
+		" X XOXOOO": {ValueLoss, 0, -1},
+		" XOX  O O": {ValueLoss, 2, 0},
+		" XOX  OOX": {ValueWin, 3, 4},
+		" XOX  OXO": {ValueWin, 1, 4},
+		" XOX  XOO": {ValueWin, 1, 0},
+		" XOX OOOX": {ValueDraw, 0, 4},
+		" XOX OOXO": {ValueLoss, 0, -1},
+		" XOX OX O": {ValueLoss, 0, -1},
+		" XOX OXOO": {ValueLoss, 0, -1},
+		" XOXO O X": {ValueLoss, 0, -1},
+		" XOXO OOX": {ValueLoss, 0, -1},
+		" XOXO OXO": {ValueLoss, 0, -1},
+		" XOXO X O": {ValueWin, 1, 0},
+		" XOXO XOO": {ValueWin, 1, 0},
+		" XOXOOOXX": {ValueLoss, 0, -1},
+		" XOXOOX O": {ValueLoss, 0, -1},
+		" XOXOOXOX": {ValueWin, 1, 0},
+		" XOXOOXXO": {ValueLoss, 0, -1},
+		" XOXOXOXO": {ValueLoss, 0, -1},
+		" XOXOXXOO": {ValueWin, 1, 0},
+		" XOXX O O": {ValueWin, 1, 7},
+		" XOXX OOO": {ValueLoss, 0, -1},
+		" XOXXOOOX": {ValueWin, 1, 0},
+		" XOXXOXOO": {ValueLoss, 0, -1},
+		" XXXOOXOO": {ValueWin, 1, 0},
+		"O O   OXX": {ValueLoss, 2, 3},
+		"O O   X X": {ValueWin, 1, 7},
+		"O O   XOX": {ValueDraw, 0, 1},
+		"O O  OX X": {ValueWin, 1, 7},
+		"O O  XOXX": {ValueLoss, 2, 4},
+		"O O  XX O": {ValueLoss, 2, 7},
+		"O O  XXOX": {ValueDraw, 0, 1},
+		"O O  XXXO": {ValueLoss, 2, 3},
+		"O O O X X": {ValueWin, 1, 7},
+		"O O OXOXX": {ValueLoss, 0, -1},
+		"O O OXX X": {ValueWin, 1, 7},
+		"O O OXXOX": {ValueDraw, 0, 1},
+		"O O OXXXO": {ValueLoss, 0, -1},
+		"O O X O X": {ValueLoss, 2, 5},
+		"O O X OXX": {ValueWin, 1, 1},
+		"O O X XOX": {ValueDraw, 0, 1},
+		"O O XOOXX": {ValueWin, 1, 1},
+		"O O XOX X": {ValueWin, 1, 7},
+		"O O XOXOX": {ValueDraw, 0, 1},
+		"O O XOXXO": {ValueLoss, 0, -1},
+		"O O XXOXO": {ValueWin, 1, 3},
+		"O O XXX O": {ValueWin, 1, 3},
+		"O O XXXOO": {ValueWin, 1, 3},
+		"O OO XOXX": {ValueLoss, 0, -1},
+		"O OO XX X": {ValueWin, 1, 7},
+		"O OO XXOX": {ValueDraw, 0, 1},
+		"O OO XXXO": {ValueLoss, 2, 4},
+		"O OOOXX X": {ValueWin, 1, 7},
+		"O OOXOX X": {ValueWin, 1, 7},
+		"O OOXXO X": {ValueLoss, 0, -1},
+		"O OOXXOXX": {ValueLoss, 0, -1},
+		"O OOXXX O": {ValueDraw, 0, 1},
+		"O OOXXXOX": {ValueDraw, 0, 1},
+		"O OOXXXXO": {ValueWin, 1, 1},
+		"O OX XO X": {ValueWin, 1, 4},
+		"O OX XOOX": {ValueWin, 1, 4},
+		"O OX XOXO": {ValueWin, 1, 4},
+		"O OXOXO X": {ValueLoss, 0, -1},
+		"O OXOXOXX": {ValueLoss, 0, -1},
+		"O OXOXXOX": {ValueDraw, 0, 1},
+		"O X   X O": {ValueWin, 1, 4},
+		"O X   XOO": {ValueWin, 1, 4},
+		"O X  OXOX": {ValueWin, 1, 4},
+		"O X  OXXO": {ValueWin, 1, 4},
+		"O X O X O": {ValueLoss, 0, -1},
+		"O X O XOX": {ValueWin, 1, 5},
+		"O X O XXO": {ValueLoss, 0, -1},
+		"O X OOXOX": {ValueLoss, 2, 1},
+		"O X OOXXO": {ValueLoss, 0, -1},
+		"O XO  O X": {ValueLoss, 0, -1},
+		"O XO  OXX": {ValueLoss, 0, -1},
+		"O XO  XOX": {ValueWin, 1, 4},
+		"O XO  XXO": {ValueWin, 1, 4},
+		"O XO OOXX": {ValueLoss, 0, -1},
+		"O XO OX X": {ValueWin, 1, 4},
+		"O XO OXOX": {ValueWin, 1, 4},
+		"O XO OXXO": {ValueWin, 1, 4},
+		"O XO XOXO": {ValueLoss, 0, -1},
+		"O XO XX O": {ValueWin, 1, 4},
+		"O XO XXOO": {ValueWin, 1, 4},
+		"O XOO OXX": {ValueLoss, 0, -1},
+		"O XOO X X": {ValueWin, 1, 7},
+		"O XOO XOX": {ValueWin, 1, 5},
+		"O XOO XXO": {ValueLoss, 0, -1},
+		"O XOOOX X": {ValueLoss, 0, -1},
+		"O XOOXX O": {ValueLoss, 0, -1},
+		"O XOOXXXO": {ValueLoss, 0, -1},
+		"O XOX O X": {ValueLoss, 0, -1},
+		"O XOX OOX": {ValueLoss, 0, -1},
+		"O XOX OXO": {ValueLoss, 0, -1},
+		"O XOXOO X": {ValueLoss, 0, -1},
+		"O XOXOOXX": {ValueLoss, 0, -1},
+		"O XOXXOXO": {ValueLoss, 0, -1},
+		"O XX  OOX": {ValueWin, 1, 5},
+		"O XX  OXO": {ValueWin, 3, 4},
+		"O XX OO X": {ValueDraw, 0, 1},
+		"O XX OOOX": {ValueDraw, 0, 4},
+		"O XX OOXO": {ValueDraw, 0, 4},
+		"O XX OXOO": {ValueWin, 1, 4},
+		"O XX XOOO": {ValueLoss, 0, -1},
+		"O XXO O X": {ValueWin, 1, 5},
+		"O XXO OOX": {ValueWin, 1, 5},
+		"O XXO OXO": {ValueLoss, 0, -1},
+		"O XXOOO X": {ValueDraw, 0, 7},
+		"O XXOOOXX": {ValueDraw, 0, 1},
+		"O XXOOXOX": {ValueDraw, 0, 1},
+		"O XXOOXXO": {ValueLoss, 0, -1},
+		"O XXOXOXO": {ValueLoss, 0, -1},
+		"O XXOXXOO": {ValueLoss, 0, -1},
+		"O XXXOOOX": {ValueDraw, 0, 1},
+		"O XXXOOXO": {ValueWin, 1, 1},
+		"OOOO XX X": {ValueLoss, 0, -1},
+		"OOOOXXOXX": {ValueLoss, 0, -1},
+		"OOOOXXX X": {ValueLoss, 0, -1},
+		"OOOOXXXOX": {ValueLoss, 0, -1},
+		"OOOOXXXXO": {ValueLoss, 0, -1},
+		"OOOX XOXX": {ValueLoss, 0, -1},
+		"OOOX XXOX": {ValueLoss, 0, -1},
+		"OOOXOXOXX": {ValueLoss, 0, -1},
+		"OOOXOXX X": {ValueLoss, 0, -1},
+		"OOOXOXXOX": {ValueLoss, 0, -1},
+		"OOXO  X X": {ValueWin, 1, 7},
+		"OOXO  XOX": {ValueWin, 1, 4},
+		"OOXO XXXO": {ValueWin, 1, 4},
+		"OOXOO X X": {ValueWin, 1, 5},
+		"OOXOOXXXO": {ValueLoss, 0, -1},
+		"OOXX  OOX": {ValueWin, 1, 5},
+		"OOXX OOXX": {ValueDraw, 0, 4},
+		"OOXX OXOX": {ValueWin, 1, 4},
+		"OOXX OXXO": {ValueWin, 1, 4},
+		"OOXX XOXO": {ValueWin, 1, 4},
+		"OOXX XXOO": {ValueWin, 1, 4},
+		"OOXXO OXX": {ValueWin, 1, 5},
+		"OOXXO XOX": {ValueLoss, 0, -1},
+		"OOXXOOOXX": {ValueDraw, 0, -1},
+		"OOXXOOX X": {ValueWin, 1, 7},
+		"OOXXOOXOX": {ValueLoss, 0, -1},
+		"OOXXOOXXO": {ValueLoss, 0, -1},
+		"OOXXOXOXO": {ValueLoss, 0, -1},
+		"OOXXOXXOO": {ValueLoss, 0, -1},
+		"OOXXX OOX": {ValueWin, 1, 5},
+		"OOXXXOOOX": {ValueDraw, 0, -1},
+		"OOXXXOOXO": {ValueDraw, 0, -1},
+		"OXOX XOXO": {ValueWin, 1, 4},
+		"OXOXOXOXO": {ValueLoss, 0, -1},
+		"X XOOOXOX": {ValueLoss, 0, -1},
+		"XOXO OXOX": {ValueWin, 1, 4},
+		"XOXOOOXOX": {ValueLoss, 0, -1},
+	}
+}
@@ -0,0 +1,107 @@
+package bot
+
+//go:generate go run ../../cmd/solve -o solved_table.go
+
+import (
+	"sort"
+	"strings"
+	"tic_tac_toe/internal/game"
+)
+
+// Solved — решённая позиция классического поля 3x3 с точки зрения того, кто ходит
+type Solved struct {
+	Value Value
+	Plies int // Полуходов до конца партии при победе или поражении
+	Best  int // Лучший ход в канонической записи позиции, -1 — партия окончена
+}
+
+// solved — таблица всех достижимых позиций 3x3 по каноническому ключу.
+// Заполняется в solved_table.go, который строит cmd/solve.
+var solved map[string]Solved
+
+// TableKey возвращает ключ позиции в таблице и перестановку клеток, которая
+// переводит поле в каноническую запись. Знаки переименовываются так, чтобы
+// ходил X: позиции, отличающиеся лишь тем, кто играет крестиками, совпадают.
+// Таблица есть только для классической партии 3x3.
+func TableKey(g *game.Game) (string, []int, bool) {
+	cfg := g.Config()
+	if cfg.Mode != game.Standard || cfg.Width != 3 || cfg.Height != 3 || cfg.WinLength != 3 || cfg.Rules != game.StandardRules {
+		return "", nil, false
+	}
+
+	board := g.Board()
+	if g.Marks(g.Turn())[0] == game.O {
+		board = strings.Map(func(r rune) rune {
+			switch game.Mark(r) {
+			case game.X:
+				return rune(game.O)
+			case game.O:
+				return rune(game.X)
+			}
+			return r
+		}, board)
+	}
+	key, perm := cfg.Canonical(board)
+	return key, perm, true
+}
+
+// lookup возвращает решённую позицию из таблицы
+func lookup(g *game.Game) (Solved, []int, bool) {
+	key, perm, ok := TableKey(g)
+	if !ok {
+		return Solved{}, nil, false
+	}
+	s, ok := solved[key]
+	return s, perm, ok
+}
+
+// tableMove возвращает лучший ход из таблицы
+func tableMove(g *game.Game) (game.Move, bool) {
+	s, perm, ok := lookup(g)
+	if !ok || s.Best < 0 {
+		return game.Move{}, false
+	}
+	return game.Move{Position: perm[s.Best], Mark: g.Marks(g.Turn())[0]}, true
+}
+
+// tableEvaluations оценивает все ходы по таблице: исход хода — это исход
+// позиции после него, взятый с точки зрения соперника
+func tableEvaluations(g *game.Game) ([]Evaluation, bool) {
+	moves := g.LegalMoves()
+	evals := make([]Evaluation, 0, len(moves))
+	for _, m := range moves {
+		child := g.Clone()
+		if _, err := child.Apply(m); err != nil {
+			return nil, false
+		}
+		s, _, ok := lookup(child)
+		if !ok {
+			return nil, false
+		}
+
+		e := Evaluation{Move: m, Value: ValueDraw}
+		switch s.Value {
+		case ValueWin:
+			e.Value, e.Plies = ValueLoss, s.Plies+1
+		case ValueLoss:
+			e.Value, e.Plies = ValueWin, s.Plies+1
+		}
+		evals = append(evals, e)
+	}
+
+	sort.SliceStable(evals, func(i, j int) bool {
+		return evaluationScore(evals[i]) > evaluationScore(evals[j])
+	})
+	return evals, len(evals) > 0
+}
+
+// evaluationScore переводит оценку в шкалу перебора
+func evaluationScore(e Evaluation) int {
+	switch e.Value {
+	case ValueWin:
+		return winScore - e.Plies
+	case ValueLoss:
+		return -(winScore - e.Plies)
+	}
+	return 0
+}
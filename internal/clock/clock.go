@@ -0,0 +1,119 @@
+package clock
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnknownKind возвращается для неизвестного вида контроля времени
+var ErrUnknownKind = errors.New("unknown time control")
+
+// Kind — вид контроля времени
+type Kind string
+
+const (
+	Fischer   Kind = "fischer"   // После хода к остатку добавляется прибавка
+	Bronstein Kind = "bronstein" // После хода возвращается потраченное время, но не больше прибавки
+)
+
+// ParseKind разбирает вид контроля; пустая строка — Фишер
+func ParseKind(s string) (Kind, error) {
+	switch Kind(s) {
+	case "":
+		return Fischer, nil
+	case Fischer, Bronstein:
+		return Kind(s), nil
+	}
+	return "", ErrUnknownKind
+}
+
+// Control — контроль времени партии
+type Control struct {
+	Kind      Kind
+	Initial   time.Duration // Время на партию, 0 — без часов
+	Increment time.Duration // Прибавка или задержка на ход
+}
+
+// Enabled сообщает, что партия идёт с часами
+func (c Control) Enabled() bool {
+	return c.Initial > 0
+}
+
+// Clock — шахматные часы двух игроков. Игроки обозначаются местами 0 и 1.
+// Время идёт только у одного игрока, остаток которого отсчитывается от Since.
+type Clock struct {
+	Control
+	Remaining [2]time.Duration // Остаток на момент Since у идущих часов и текущий у стоящих
+	Running   int              // Чьё время идёт, -1 — часы стоят
+	Since     time.Time
+}
+
+// New возвращает остановленные часы с полным временем у обоих игроков
+func New(c Control) Clock {
+	return Clock{Control: c, Remaining: [2]time.Duration{c.Initial, c.Initial}, Running: -1}
+}
+
+// Left возвращает остаток игрока на момент now, не меньше нуля
+func (c Clock) Left(side int, now time.Time) time.Duration {
+	left := c.Remaining[side]
+	if side == c.Running {
+		left -= now.Sub(c.Since)
+	}
+	return max(left, 0)
+}
+
+// Flagged сообщает, что у игрока, чьё время идёт, флаг упал
+func (c Clock) Flagged(now time.Time) bool {
+	return c.Enabled() && c.Running >= 0 && c.Left(c.Running, now) == 0
+}
+
+// Deadline возвращает момент падения флага; нулевое время, если часы стоят
+func (c Clock) Deadline() time.Time {
+	if !c.Enabled() || c.Running < 0 {
+		return time.Time{}
+	}
+	return c.Since.Add(c.Remaining[c.Running])
+}
+
+// Start запускает время игрока
+func (c *Clock) Start(side int, now time.Time) {
+	if !c.Enabled() {
+		return
+	}
+	c.Running, c.Since = side, now
+}
+
+// Stop останавливает часы, списав потраченное время
+func (c *Clock) Stop(now time.Time) {
+	if c.Running < 0 {
+		return
+	}
+	c.Remaining[c.Running] = c.Left(c.Running, now)
+	c.Running = -1
+}
+
+// Press отмечает ход игрока: списывает потраченное время, начисляет прибавку
+// и запускает время соперника. До первого хода часы стоят, и он ничего не стоит.
+// Возвращает false, если флаг игрока упал раньше хода.
+func (c *Clock) Press(side int, now time.Time) bool {
+	if !c.Enabled() {
+		return true
+	}
+	if c.Running == side {
+		spent := now.Sub(c.Since)
+		if spent >= c.Remaining[side] {
+			c.Remaining[side] = 0
+			c.Running = -1
+			return false
+		}
+		c.Remaining[side] -= spent
+		switch c.Kind {
+		case Bronstein:
+			c.Remaining[side] += min(spent, c.Increment)
+		default:
+			c.Remaining[side] += c.Increment
+		}
+	}
+	c.Start(1-side, now)
+	return true
+}
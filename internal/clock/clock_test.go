@@ -0,0 +1,164 @@
+package clock
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// running возвращает часы, на которых с момента start идёт время игрока 0
+func running(kind Kind, initial, increment time.Duration) Clock {
+	c := New(Control{Kind: kind, Initial: initial, Increment: increment})
+	c.Start(0, start)
+	return c
+}
+
+func TestParseKind(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Kind
+		err  error
+	}{
+		{"", Fischer, nil},
+		{"fischer", Fischer, nil},
+		{"bronstein", Bronstein, nil},
+		{"hourglass", "", ErrUnknownKind},
+	}
+	for _, tt := range tests {
+		got, err := ParseKind(tt.in)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("ParseKind(%q) = %q, %v; want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestPress(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      Kind
+		increment time.Duration
+		spent     time.Duration
+		ok        bool
+		want      time.Duration // Остаток игрока 0 после хода
+	}{
+		{"fischer", Fischer, 2 * time.Second, 10 * time.Second, true, 52 * time.Second},
+		{"fischer adds more than spent", Fischer, 5 * time.Second, time.Second, true, 64 * time.Second},
+		{"fischer without increment", Fischer, 0, 10 * time.Second, true, 50 * time.Second},
+		{"bronstein returns the delay", Bronstein, 2 * time.Second, 10 * time.Second, true, 52 * time.Second},
+		{"bronstein capped at time used", Bronstein, 5 * time.Second, time.Second, true, time.Minute},
+		{"bronstein exact delay", Bronstein, 3 * time.Second, 3 * time.Second, true, time.Minute},
+		{"last millisecond", Fischer, 2 * time.Second, time.Minute - time.Millisecond, true, 2*time.Second + time.Millisecond},
+		{"flag fell", Fischer, 2 * time.Second, time.Minute, false, 0},
+		{"flag fell long ago", Bronstein, 2 * time.Second, time.Hour, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := running(tt.kind, time.Minute, tt.increment)
+			now := start.Add(tt.spent)
+			if ok := c.Press(0, now); ok != tt.ok {
+				t.Fatalf("Press() = %v, want %v", ok, tt.ok)
+			}
+			if c.Remaining[0] != tt.want {
+				t.Errorf("remaining %v, want %v", c.Remaining[0], tt.want)
+			}
+			if c.Remaining[1] != time.Minute {
+				t.Errorf("opponent's remaining %v", c.Remaining[1])
+			}
+
+			// После хода идёт время соперника, после падения флага часы стоят
+			wantRunning := 1
+			if !tt.ok {
+				wantRunning = -1
+			}
+			if c.Running != wantRunning {
+				t.Errorf("running %d, want %d", c.Running, wantRunning)
+			}
+			if tt.ok && !c.Since.Equal(now) {
+				t.Errorf("since %v, want %v", c.Since, now)
+			}
+		})
+	}
+}
+
+func TestPressFirstMove(t *testing.T) {
+	// До первого хода часы стоят, и он ничего не стоит, даже с прибавкой
+	c := New(Control{Kind: Fischer, Initial: time.Minute, Increment: 2 * time.Second})
+	if !c.Press(0, start) {
+		t.Fatal("first move flagged")
+	}
+	if c.Remaining != [2]time.Duration{time.Minute, time.Minute} || c.Running != 1 || !c.Since.Equal(start) {
+		t.Errorf("clock after first move = %+v", c)
+	}
+
+	// Без часов ход ничего не меняет
+	off := New(Control{})
+	if !off.Press(0, start) || off.Running != -1 {
+		t.Errorf("disabled clock after move = %+v", off)
+	}
+}
+
+func TestFlaggedAndDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		clock    func() Clock
+		elapsed  time.Duration
+		flagged  bool
+		left     time.Duration // Остаток игрока 0
+		deadline time.Duration // От start, -1 — нулевое время
+	}{
+		{"running", func() Clock { return running(Fischer, time.Minute, 0) }, 20 * time.Second, false, 40 * time.Second, time.Minute},
+		{"at the deadline", func() Clock { return running(Fischer, time.Minute, 0) }, time.Minute, true, 0, time.Minute},
+		{"past the deadline", func() Clock { return running(Bronstein, time.Minute, time.Second) }, 2 * time.Minute, true, 0, time.Minute},
+		{"stopped", func() Clock { return New(Control{Initial: time.Minute}) }, time.Hour, false, time.Minute, -1},
+		{"disabled", func() Clock { c := New(Control{}); c.Start(0, start); return c }, time.Hour, false, 0, -1},
+		{"opponent's time runs", func() Clock {
+			c := New(Control{Initial: time.Minute})
+			c.Start(1, start)
+			return c
+		}, 30 * time.Second, false, time.Minute, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.clock()
+			now := start.Add(tt.elapsed)
+			if got := c.Flagged(now); got != tt.flagged {
+				t.Errorf("Flagged() = %v, want %v", got, tt.flagged)
+			}
+			if got := c.Left(0, now); got != tt.left {
+				t.Errorf("Left(0) = %v, want %v", got, tt.left)
+			}
+			want := time.Time{}
+			if tt.deadline >= 0 {
+				want = start.Add(tt.deadline)
+			}
+			if got := c.Deadline(); !got.Equal(want) {
+				t.Errorf("Deadline() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestStop(t *testing.T) {
+	c := running(Fischer, time.Minute, 2*time.Second)
+	c.Stop(start.Add(15 * time.Second))
+	// Остановка не ход: прибавка не начисляется
+	if c.Running != -1 || c.Remaining[0] != 45*time.Second {
+		t.Errorf("stopped clock = %+v", c)
+	}
+	c.Stop(start.Add(time.Hour))
+	if c.Remaining[0] != 45*time.Second {
+		t.Errorf("second stop changed remaining to %v", c.Remaining[0])
+	}
+	if c.Flagged(start.Add(time.Hour)) {
+		t.Error("stopped clock flagged")
+	}
+
+	// Флаг, упавший до остановки, остаётся упавшим: остаток не меньше нуля
+	late := running(Fischer, time.Minute, 0)
+	late.Stop(start.Add(2 * time.Minute))
+	if late.Remaining[0] != 0 {
+		t.Errorf("remaining after late stop %v", late.Remaining[0])
+	}
+}
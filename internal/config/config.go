@@ -2,14 +2,21 @@ package config
 
 import (
 	"fmt"
+	"tic_tac_toe/internal/archive"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/repository"
 	"tic_tac_toe/pkg/db/redis"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
-	HTTPServerPort int `env:"HTTP_SERVER_PORT" env-default:"8080"`
+	HTTPServerPort int    `env:"HTTP_SERVER_PORT" env-default:"8080"`
+	Storage        string `env:"STORAGE" env-default:"redis"` // redis или memory — комнаты в памяти, без Redis
 	redis.ConfigRedis
+	bot.Config
+	Archive archive.Config
+	RoomTTL repository.TTL
 }
 
 func New() *Config {
@@ -20,6 +27,19 @@ func New() *Config {
 		return nil
 	}
 
-	fmt.Printf("Config loaded: %+v\n", cfg)
+	fmt.Printf("Config loaded: %+v\n", cfg.redacted())
 	return &cfg
 }
+
+// redacted возвращает копию конфигурации без паролей, пригодную для логов.
+// Строка подключения к Postgres содержит логин и пароль, поэтому скрывается
+// целиком; у SQLite это просто путь к файлу.
+func (cfg Config) redacted() Config {
+	if cfg.Password != "" {
+		cfg.Password = "***"
+	}
+	if cfg.Archive.Driver != "sqlite" && cfg.Archive.DSN != "" {
+		cfg.Archive.DSN = "***"
+	}
+	return cfg
+}
@@ -0,0 +1,190 @@
+package game
+
+// Ограничения на размер поля
+const (
+	MinSize = 3
+	MaxSize = 19
+)
+
+// Mode — вариант игрового поля
+type Mode string
+
+const (
+	Standard Mode = "standard" // Прямоугольное поле, k в ряд
+	Ultimate Mode = "ultimate" // Поле 3x3 из полей 3x3
+	Gravity  Mode = "gravity"  // Фишка падает на дно столбца, как в «Четыре в ряд»
+	Cube     Mode = "cube"     // Трёхмерное поле, по умолчанию 4x4x4 (Qubic)
+)
+
+// ParseMode разбирает название режима; пустая строка — стандартный режим
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", Standard:
+		return Standard, nil
+	case Ultimate, Gravity, Cube:
+		return Mode(s), nil
+	}
+	return "", ErrInvalidConfig
+}
+
+// Config — режим, геометрия поля, длина выигрышной линии и набор правил
+type Config struct {
+	Mode      Mode
+	Width     int
+	Height    int
+	Depth     int // Количество слоёв, больше одного только в режиме куба
+	WinLength int
+	Rules     Rules
+	FirstMark Mark // Знак первого игрока в правилах, где у каждого свой знак; по умолчанию X
+}
+
+// Classic возвращает конфигурацию классических крестиков-ноликов 3x3
+func Classic() Config {
+	return Config{Mode: Standard, Width: 3, Height: 3, Depth: 1, WinLength: 3, Rules: StandardRules}
+}
+
+// WithDefaults подставляет значения по умолчанию для незаданных параметров:
+// поле 3x3 (7x6 и четыре в ряд в режиме с падением фишек, 6x6 и пять в ряд
+// в «Порядке и Хаосе», куб 4x4x4), а длина линии — меньшая сторона поля,
+// но не больше пяти. Размеры поля в ультимативном режиме фиксированы.
+func (c Config) WithDefaults() Config {
+	if c.Mode == "" {
+		c.Mode = Standard
+	}
+	if c.Rules == "" {
+		c.Rules = StandardRules
+	}
+	if c.Mode == Ultimate {
+		c.Width, c.Height, c.Depth, c.WinLength = 9, 9, 1, 3
+		return c
+	}
+	if c.Mode == Cube {
+		// Куб всегда равносторонний, линия проходит его насквозь
+		n := max(c.Width, c.Height, c.Depth)
+		if n == 0 {
+			n = 4
+		}
+		c.Width, c.Height, c.Depth, c.WinLength = n, n, n, n
+		return c
+	}
+	c.Depth = 1
+	if c.Mode == Gravity && c.Width == 0 && c.Height == 0 {
+		c.Width, c.Height = 7, 6
+		if c.WinLength == 0 {
+			c.WinLength = 4
+		}
+	}
+	if c.Rules == OrderChaosRules && c.Width == 0 && c.Height == 0 {
+		c.Width, c.Height = 6, 6
+	}
+
+	if c.Width == 0 {
+		c.Width = 3
+	}
+	if c.Height == 0 {
+		c.Height = c.Width
+	}
+	if c.WinLength == 0 {
+		c.WinLength = min(c.Width, c.Height, 5)
+	}
+	return c
+}
+
+// Validate проверяет, что на таком поле можно сыграть
+func (c Config) Validate() error {
+	if _, err := ParseRules(string(c.Rules)); err != nil {
+		return err
+	}
+	switch c.FirstMark {
+	case 0, Empty, X, O:
+	default:
+		return ErrInvalidMark
+	}
+
+	switch c.Mode {
+	case Standard, Gravity:
+		if c.depth() != 1 {
+			return ErrInvalidConfig
+		}
+	case Ultimate:
+		if c.Width != 9 || c.Height != 9 || c.depth() != 1 || c.WinLength != 3 {
+			return ErrInvalidConfig
+		}
+		return nil
+	case Cube:
+		n := c.Width
+		if n < 3 || n > 5 || c.Height != n || c.Depth != n || c.WinLength != n {
+			return ErrInvalidConfig
+		}
+		return nil
+	default:
+		return ErrInvalidConfig
+	}
+
+	if c.Width < MinSize || c.Width > MaxSize || c.Height < MinSize || c.Height > MaxSize {
+		return ErrInvalidConfig
+	}
+	if c.WinLength < 3 || c.WinLength > max(c.Width, c.Height) {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// Cells возвращает количество клеток поля
+func (c Config) Cells() int {
+	return c.Width * c.Height * c.depth()
+}
+
+// depth возвращает количество слоёв; незаданная глубина — плоское поле
+func (c Config) depth() int {
+	return max(c.Depth, 1)
+}
+
+// Index переводит координаты (строка, столбец) в номер клетки.
+// В ультимативном режиме клетки хранятся по подполям: подполе*9 + клетка.
+func (c Config) Index(row, col int) (int, error) {
+	if row < 0 || row >= c.Height || col < 0 || col >= c.Width {
+		return 0, ErrInvalidPosition
+	}
+	if c.Mode == Ultimate {
+		return UltimateIndex((row/3)*3+col/3, (row%3)*3+col%3)
+	}
+	return row*c.Width + col, nil
+}
+
+// Coords переводит номер клетки в координаты (строка, столбец)
+func (c Config) Coords(pos int) (row, col int) {
+	if c.Mode == Ultimate {
+		sub, cell := pos/9, pos%9
+		return (sub/3)*3 + cell/3, (sub%3)*3 + cell%3
+	}
+	return pos / c.Width, pos % c.Width
+}
+
+// Index3 переводит координаты (слой, строка, столбец) в номер клетки.
+// Клетки хранятся послойно: слой*Width*Height + строка*Width + столбец.
+func (c Config) Index3(layer, row, col int) (int, error) {
+	if layer < 0 || layer >= c.depth() || row < 0 || row >= c.Height || col < 0 || col >= c.Width {
+		return 0, ErrInvalidPosition
+	}
+	return c.index3(layer, row, col), nil
+}
+
+// Coords3 переводит номер клетки в координаты (слой, строка, столбец)
+func (c Config) Coords3(pos int) (layer, row, col int) {
+	plane := c.Width * c.Height
+	row, col = c.Coords(pos % plane)
+	return pos / plane, row, col
+}
+
+func (c Config) index3(layer, row, col int) int {
+	return (layer*c.Height+row)*c.Width + col
+}
+
+// UltimateIndex переводит пару (подполе, клетка подполя) в номер клетки
+func UltimateIndex(sub, cell int) (int, error) {
+	if sub < 0 || sub >= 9 || cell < 0 || cell >= 9 {
+		return 0, ErrInvalidPosition
+	}
+	return sub*9 + cell, nil
+}
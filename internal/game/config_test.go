@@ -0,0 +1,139 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want Config
+	}{
+		{"empty", Config{}, Config{Mode: Standard, Width: 3, Height: 3, Depth: 1, WinLength: 3, Rules: StandardRules}},
+		{"wide", Config{Width: 10, Height: 7}, Config{Mode: Standard, Width: 10, Height: 7, Depth: 1, WinLength: 5, Rules: StandardRules}},
+		{"square by width", Config{Width: 4}, Config{Mode: Standard, Width: 4, Height: 4, Depth: 1, WinLength: 4, Rules: StandardRules}},
+		{"gravity", Config{Mode: Gravity}, Config{Mode: Gravity, Width: 7, Height: 6, Depth: 1, WinLength: 4, Rules: StandardRules}},
+		{"order and chaos", Config{Rules: OrderChaosRules}, Config{Mode: Standard, Width: 6, Height: 6, Depth: 1, WinLength: 5, Rules: OrderChaosRules}},
+		{"ultimate ignores size", Config{Mode: Ultimate, Width: 5}, Config{Mode: Ultimate, Width: 9, Height: 9, Depth: 1, WinLength: 3, Rules: StandardRules}},
+		{"cube", Config{Mode: Cube}, Config{Mode: Cube, Width: 4, Height: 4, Depth: 4, WinLength: 4, Rules: StandardRules}},
+		{"cube by depth", Config{Mode: Cube, Depth: 3}, Config{Mode: Cube, Width: 3, Height: 3, Depth: 3, WinLength: 3, Rules: StandardRules}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.WithDefaults(); got != tt.want {
+				t.Errorf("WithDefaults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want error
+	}{
+		{"classic", Classic(), nil},
+		{"defaults", Config{}.WithDefaults(), nil},
+		{"gomoku", Config{Mode: Standard, Width: 15, Height: 15, WinLength: 5}, nil},
+		{"too small", Config{Mode: Standard, Width: 2, Height: 2, WinLength: 2}, ErrInvalidConfig},
+		{"too large", Config{Mode: Standard, Width: MaxSize + 1, Height: 3, WinLength: 3}, ErrInvalidConfig},
+		{"line longer than board", Config{Mode: Standard, Width: 3, Height: 3, WinLength: 4}, ErrInvalidConfig},
+		{"short line", Config{Mode: Standard, Width: 5, Height: 5, WinLength: 2}, ErrInvalidConfig},
+		{"flat board with layers", Config{Mode: Standard, Width: 3, Height: 3, Depth: 2, WinLength: 3}, ErrInvalidConfig},
+		{"ultimate", Config{Mode: Ultimate}.WithDefaults(), nil},
+		{"ultimate resized", Config{Mode: Ultimate, Width: 6, Height: 6, WinLength: 3}, ErrInvalidConfig},
+		{"cube", Config{Mode: Cube}.WithDefaults(), nil},
+		{"cube not equilateral", Config{Mode: Cube, Width: 4, Height: 4, Depth: 3, WinLength: 4}, ErrInvalidConfig},
+		{"cube too large", Config{Mode: Cube, Width: 6, Height: 6, Depth: 6, WinLength: 6}, ErrInvalidConfig},
+		{"unknown mode", Config{Mode: "hex", Width: 3, Height: 3, WinLength: 3}, ErrInvalidConfig},
+		{"unknown rules", Config{Mode: Standard, Width: 3, Height: 3, WinLength: 3, Rules: "chess"}, ErrInvalidConfig},
+		{"first mark O", Config{Mode: Standard, Width: 3, Height: 3, WinLength: 3, FirstMark: O}, nil},
+		{"bad first mark", Config{Mode: Standard, Width: 3, Height: 3, WinLength: 3, FirstMark: 'Z'}, ErrInvalidMark},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexCoords(t *testing.T) {
+	configs := []Config{
+		Classic(),
+		{Mode: Standard, Width: 7, Height: 5, Depth: 1, WinLength: 4},
+		Config{Mode: Ultimate}.WithDefaults(),
+		Config{Mode: Gravity}.WithDefaults(),
+	}
+	for _, cfg := range configs {
+		seen := make(map[int]bool)
+		for row := 0; row < cfg.Height; row++ {
+			for col := 0; col < cfg.Width; col++ {
+				pos, err := cfg.Index(row, col)
+				if err != nil {
+					t.Fatalf("%s: Index(%d, %d) = %v", cfg.Mode, row, col, err)
+				}
+				if seen[pos] {
+					t.Fatalf("%s: Index(%d, %d) = %d twice", cfg.Mode, row, col, pos)
+				}
+				seen[pos] = true
+				if r, c := cfg.Coords(pos); r != row || c != col {
+					t.Errorf("%s: Coords(%d) = (%d, %d), want (%d, %d)", cfg.Mode, pos, r, c, row, col)
+				}
+			}
+		}
+		if _, err := cfg.Index(cfg.Height, 0); !errors.Is(err, ErrInvalidPosition) {
+			t.Errorf("%s: Index outside the board = %v, want %v", cfg.Mode, err, ErrInvalidPosition)
+		}
+	}
+
+	cube := Config{Mode: Cube}.WithDefaults()
+	for pos := 0; pos < cube.Cells(); pos++ {
+		layer, row, col := cube.Coords3(pos)
+		if got, err := cube.Index3(layer, row, col); err != nil || got != pos {
+			t.Errorf("Index3(Coords3(%d)) = %d, %v", pos, got, err)
+		}
+	}
+}
+
+func TestWindows(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want int
+	}{
+		{"classic", Classic(), 8},
+		{"4x4 three in a row", Config{Mode: Standard, Width: 4, Height: 4, Depth: 1, WinLength: 3}, 24},
+		{"connect four", Config{Mode: Gravity}.WithDefaults(), 69},
+		{"qubic", Config{Mode: Cube}.WithDefaults(), 76},
+		{"ultimate", Config{Mode: Ultimate}.WithDefaults(), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(tt.cfg.Windows()); got != tt.want {
+				t.Errorf("len(Windows()) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	cfg := Classic()
+	corners := []string{"X        ", "  X      ", "      X  ", "        X"}
+	want, _ := cfg.Canonical(corners[0])
+	for _, board := range corners {
+		got, perm := cfg.Canonical(board)
+		if got != want {
+			t.Errorf("Canonical(%q) = %q, want %q", board, got, want)
+		}
+		for i, p := range perm {
+			if got[i] != board[p] {
+				t.Fatalf("Canonical(%q): perm does not map the board", board)
+			}
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package game
+
+import "errors"
+
+// Ошибки проверки ходов
+var (
+	ErrInvalidConfig   = errors.New("invalid board configuration")
+	ErrInvalidBoard    = errors.New("invalid board")
+	ErrInvalidMark     = errors.New("invalid mark")
+	ErrInvalidPosition = errors.New("invalid position")
+	ErrOccupied        = errors.New("position already occupied")
+	ErrWrongBoard      = errors.New("move must be played in the required board")
+	ErrBoardClosed     = errors.New("board is already decided")
+	ErrColumnFull      = errors.New("column is full")
+	ErrNotLanded       = errors.New("piece must be dropped to the lowest empty cell")
+	ErrWrongTurn       = errors.New("it's not your turn")
+	ErrGameOver        = errors.New("game is already over")
+)
@@ -0,0 +1,310 @@
+package game
+
+import "strings"
+
+// Mark — содержимое клетки поля
+type Mark byte
+
+const (
+	Empty Mark = ' '
+	X     Mark = 'X'
+	O     Mark = 'O'
+)
+
+// Opponent возвращает знак соперника
+func (m Mark) Opponent() Mark {
+	if m == X {
+		return O
+	}
+	return X
+}
+
+func (m Mark) String() string {
+	if m == Empty {
+		return ""
+	}
+	return string(m)
+}
+
+// ParseMark разбирает знак игрока ("X" или "O")
+func ParseMark(s string) (Mark, error) {
+	switch strings.ToUpper(s) {
+	case "X":
+		return X, nil
+	case "O":
+		return O, nil
+	}
+	return Empty, ErrInvalidMark
+}
+
+// Player — участник партии: First ходит первым, Second — вторым
+type Player int
+
+const (
+	First Player = iota
+	Second
+)
+
+// Other возвращает соперника
+func (p Player) Other() Player {
+	return 1 - p
+}
+
+// Move — ход: клетка и знак, который в неё ставится
+type Move struct {
+	Position int
+	Mark     Mark
+}
+
+// Outcome — состояние партии
+type Outcome string
+
+const (
+	Ongoing Outcome = "ongoing"
+	Win     Outcome = "win"
+	Draw    Outcome = "draw"
+)
+
+// Result — итог партии после очередного хода
+type Result struct {
+	Outcome Outcome
+	Winner  Player // Имеет смысл только при Outcome == Win
+	Line    []int  // Клетки выигрышной линии
+}
+
+// Finished сообщает, закончена ли партия
+func (r Result) Finished() bool {
+	return r.Outcome != Ongoing
+}
+
+// Game — состояние партии без привязки к хранилищу и транспорту
+type Game struct {
+	cfg    Config
+	layout layout
+	rules  RuleSet
+	board  []Mark
+	turn   Player
+	next   int // Подполе, в котором обязан ходить игрок (ультимативный режим), -1 — любое
+	result Result
+}
+
+// State — сохраняемое состояние партии
+type State struct {
+	Board string
+	Turn  Player
+	Next  int
+}
+
+// Lines — то, что видно на поле после хода: образованная линия и заполненность
+type Lines struct {
+	Line []int // Клетки линии, nil — линии нет
+	Mark Mark  // Знак, из которого состоит линия
+	Full bool  // Ходов больше нет
+}
+
+// layout — правила, зависящие от режима поля
+type layout interface {
+	// check проверяет, что в клетку можно ходить
+	check(g *Game, pos int) error
+	// played ищет линии после хода в клетку
+	played(g *Game, pos int) Lines
+	// evaluate ищет линии по всему полю
+	evaluate(g *Game) Lines
+}
+
+func layoutFor(mode Mode) layout {
+	switch mode {
+	case Ultimate:
+		return ultimateLayout{}
+	case Gravity:
+		return gravityLayout{}
+	}
+	return standardLayout{}
+}
+
+// New создаёт пустое поле заданного размера, первым ходит First
+func New(cfg Config) (*Game, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	board := make([]Mark, cfg.Cells())
+	for i := range board {
+		board[i] = Empty
+	}
+	return &Game{
+		cfg:    cfg,
+		layout: layoutFor(cfg.Mode),
+		rules:  cfg.ruleSet(),
+		board:  board,
+		turn:   First,
+		next:   -1,
+		result: Result{Outcome: Ongoing},
+	}, nil
+}
+
+// Parse восстанавливает партию из сохранённого состояния
+func Parse(cfg Config, st State) (*Game, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if len(st.Board) != cfg.Cells() {
+		return nil, ErrInvalidBoard
+	}
+	if st.Turn != First && st.Turn != Second {
+		return nil, ErrWrongTurn
+	}
+	if st.Next < -1 || st.Next >= 9 || (cfg.Mode != Ultimate && st.Next != -1) {
+		return nil, ErrInvalidBoard
+	}
+
+	g := &Game{
+		cfg:    cfg,
+		layout: layoutFor(cfg.Mode),
+		rules:  cfg.ruleSet(),
+		board:  make([]Mark, len(st.Board)),
+		turn:   st.Turn,
+		next:   st.Next,
+	}
+	for i := 0; i < len(st.Board); i++ {
+		switch m := Mark(st.Board[i]); m {
+		case Empty, X, O:
+			g.board[i] = m
+		default:
+			return nil, ErrInvalidBoard
+		}
+	}
+	// Последним ходил соперник того, чья сейчас очередь
+	g.result = g.rules.Judge(g.turn.Other(), g.layout.evaluate(g))
+	return g, nil
+}
+
+// Config возвращает параметры партии
+func (g *Game) Config() Config {
+	return g.cfg
+}
+
+// Board возвращает поле в виде строки (как оно хранится в комнате)
+func (g *Game) Board() string {
+	b := make([]byte, len(g.board))
+	for i, m := range g.board {
+		b[i] = byte(m)
+	}
+	return string(b)
+}
+
+// State возвращает состояние партии для сохранения
+func (g *Game) State() State {
+	return State{Board: g.Board(), Turn: g.turn, Next: g.next}
+}
+
+// Turn возвращает игрока, который ходит следующим
+func (g *Game) Turn() Player {
+	return g.turn
+}
+
+// Marks возвращает знаки, которые может поставить игрок
+func (g *Game) Marks(p Player) []Mark {
+	return g.rules.Marks(p)
+}
+
+// Result возвращает текущий итог партии
+func (g *Game) Result() Result {
+	return g.result
+}
+
+// Clone возвращает независимую копию партии
+func (g *Game) Clone() *Game {
+	c := *g
+	c.board = append([]Mark(nil), g.board...)
+	c.result.Line = append([]int(nil), g.result.Line...)
+	return &c
+}
+
+// LegalMoves возвращает все допустимые ходы текущего игрока
+func (g *Game) LegalMoves() []Move {
+	if g.result.Finished() {
+		return nil
+	}
+	marks := g.rules.Marks(g.turn)
+	moves := make([]Move, 0, len(g.board)*len(marks))
+	for i, m := range g.board {
+		if m == Empty && g.layout.check(g, i) == nil {
+			for _, mark := range marks {
+				moves = append(moves, Move{Position: i, Mark: mark})
+			}
+		}
+	}
+	return moves
+}
+
+// Apply проверяет и применяет ход текущего игрока, передавая очередь сопернику.
+// Если знак не указан (Empty или нулевое значение), а правила разрешают игроку
+// только один знак, ставится он.
+func (g *Game) Apply(move Move) (Result, error) {
+	if g.result.Finished() {
+		return g.result, ErrGameOver
+	}
+
+	marks := g.rules.Marks(g.turn)
+	if (move.Mark == Empty || move.Mark == 0) && len(marks) == 1 {
+		move.Mark = marks[0]
+	}
+	if !allowed(marks, move.Mark) {
+		return g.result, ErrInvalidMark
+	}
+	if move.Position < 0 || move.Position >= len(g.board) {
+		return g.result, ErrInvalidPosition
+	}
+	if g.board[move.Position] != Empty {
+		return g.result, ErrOccupied
+	}
+	if err := g.layout.check(g, move.Position); err != nil {
+		return g.result, err
+	}
+
+	mover := g.turn
+	g.board[move.Position] = move.Mark
+	g.turn = g.turn.Other()
+	g.result = g.rules.Judge(mover, g.layout.played(g, move.Position))
+	return g.result, nil
+}
+
+func allowed(marks []Mark, mark Mark) bool {
+	for _, m := range marks {
+		if m == mark {
+			return true
+		}
+	}
+	return false
+}
+
+// standardLayout — прямоугольное поле, линия — WinLength одинаковых знаков
+type standardLayout struct{}
+
+func (standardLayout) check(*Game, int) error {
+	return nil
+}
+
+// Новая линия может пройти только через последний ход
+func (standardLayout) played(g *Game, pos int) Lines {
+	return Lines{Line: g.lineThrough(pos), Mark: g.board[pos], Full: g.full()}
+}
+
+func (standardLayout) evaluate(g *Game) Lines {
+	l := Lines{Line: g.findLine(), Full: g.full()}
+	if l.Line != nil {
+		l.Mark = g.board[l.Line[0]]
+	}
+	return l
+}
+
+// full сообщает, что свободных клеток не осталось
+func (g *Game) full() bool {
+	for _, m := range g.board {
+		if m == Empty {
+			return false
+		}
+	}
+	return true
+}
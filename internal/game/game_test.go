@@ -0,0 +1,240 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// play применяет ходы по очереди и возвращает итог последнего
+func play(t *testing.T, g *Game, moves ...Move) Result {
+	t.Helper()
+	var r Result
+	for i, m := range moves {
+		var err error
+		if r, err = g.Apply(m); err != nil {
+			t.Fatalf("move %d (%+v): %v", i+1, m, err)
+		}
+	}
+	return r
+}
+
+// at — ход в клетку без явного знака
+func at(positions ...int) []Move {
+	moves := make([]Move, len(positions))
+	for i, p := range positions {
+		moves[i] = Move{Position: p}
+	}
+	return moves
+}
+
+func newGame(t *testing.T, cfg Config) *Game {
+	t.Helper()
+	g, err := New(cfg.WithDefaults())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return g
+}
+
+func TestStandardOutcomes(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		moves  []int
+		want   Outcome
+		winner Player
+		line   []int
+	}{
+		{"row", Classic(), []int{0, 3, 1, 4, 2}, Win, First, []int{0, 1, 2}},
+		{"column", Classic(), []int{0, 1, 3, 4, 6}, Win, First, []int{0, 3, 6}},
+		{"anti-diagonal by O", Classic(), []int{0, 2, 1, 4, 8, 6}, Win, Second, []int{2, 4, 6}},
+		{"draw", Classic(), []int{0, 1, 2, 4, 3, 5, 7, 6, 8}, Draw, First, nil},
+		{"ongoing", Classic(), []int{4, 0}, Ongoing, First, nil},
+		{"four on 5x5", Config{Width: 5, WinLength: 4}, []int{0, 5, 6, 10, 12, 15, 18}, Win, First, []int{0, 6, 12, 18}},
+		{"overline counts", Config{Width: 6, WinLength: 3}, []int{0, 6, 1, 8, 3, 10, 2}, Win, First, []int{0, 1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := play(t, newGame(t, tt.cfg), at(tt.moves...)...)
+			if r.Outcome != tt.want {
+				t.Fatalf("outcome = %s, want %s", r.Outcome, tt.want)
+			}
+			if r.Outcome == Win && (r.Winner != tt.winner || !reflect.DeepEqual(r.Line, tt.line)) {
+				t.Errorf("winner %d line %v, want %d line %v", r.Winner, r.Line, tt.winner, tt.line)
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		moves []Move
+		bad   Move
+		want  error
+	}{
+		{"occupied", Classic(), at(4), Move{Position: 4}, ErrOccupied},
+		{"outside", Classic(), nil, Move{Position: 9}, ErrInvalidPosition},
+		{"negative", Classic(), nil, Move{Position: -1}, ErrInvalidPosition},
+		{"foreign mark", Classic(), nil, Move{Position: 0, Mark: O}, ErrInvalidMark},
+		{"game over", Classic(), at(0, 3, 1, 4, 2), Move{Position: 8}, ErrGameOver},
+		{"wild needs a mark", Config{Rules: WildRules}, nil, Move{Position: 0}, ErrInvalidMark},
+		{"floating piece", Config{Mode: Gravity}, nil, Move{Position: 0}, ErrNotLanded},
+		{"wrong sub-board", Config{Mode: Ultimate}, at(4), Move{Position: 0}, ErrWrongBoard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newGame(t, tt.cfg)
+			play(t, g, tt.moves...)
+			if _, err := g.Apply(tt.bad); !errors.Is(err, tt.want) {
+				t.Errorf("Apply(%+v) = %v, want %v", tt.bad, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	g := newGame(t, Classic())
+	play(t, g, at(4, 0, 8)...)
+
+	restored, err := Parse(g.Config(), g.State())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if restored.Board() != g.Board() || restored.Turn() != Second {
+		t.Fatalf("restored %q turn %d, want %q turn %d", restored.Board(), restored.Turn(), g.Board(), Second)
+	}
+	if !reflect.DeepEqual(restored.LegalMoves(), g.LegalMoves()) {
+		t.Errorf("restored game has different legal moves")
+	}
+
+	// Выигранная позиция восстанавливается законченной
+	won, err := Parse(Classic(), State{Board: "XXXOO    ", Turn: Second, Next: -1})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if r := won.Result(); r.Outcome != Win || r.Winner != First {
+		t.Errorf("result = %+v, want a win of the first player", r)
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		st   State
+		want error
+	}{
+		{"short board", Classic(), State{Board: "X", Next: -1}, ErrInvalidBoard},
+		{"unknown mark", Classic(), State{Board: "Z        ", Next: -1}, ErrInvalidBoard},
+		{"bad turn", Classic(), State{Board: strings.Repeat(" ", 9), Turn: 2, Next: -1}, ErrWrongTurn},
+		{"next outside ultimate", Classic(), State{Board: strings.Repeat(" ", 9), Next: 3}, ErrInvalidBoard},
+		{"bad config", Config{Width: 2, Height: 2, WinLength: 2}, State{Board: "    ", Next: -1}, ErrInvalidConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.cfg, tt.st); !errors.Is(err, tt.want) {
+				t.Errorf("Parse = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	g := newGame(t, Classic())
+	c := g.Clone()
+	play(t, c, at(4)...)
+	if g.Board() != strings.Repeat(" ", 9) || g.Turn() != First {
+		t.Errorf("move on a clone changed the original: %q", g.Board())
+	}
+}
+
+func TestGravity(t *testing.T) {
+	g := newGame(t, Config{Mode: Gravity})
+	w := g.Config().Width
+
+	pos, err := g.Drop(3)
+	if err != nil || pos != 5*w+3 {
+		t.Fatalf("Drop(3) = %d, %v, want %d", pos, err, 5*w+3)
+	}
+
+	// X бросает в столбец 0 четыре раза, O отвечает в столбец 1
+	r := Result{Outcome: Ongoing}
+	for i := 0; i < 4; i++ {
+		for _, col := range []int{0, 1} {
+			if r.Finished() {
+				break
+			}
+			pos, err := g.Drop(col)
+			if err != nil {
+				t.Fatalf("Drop(%d): %v", col, err)
+			}
+			r = play(t, g, Move{Position: pos})
+		}
+	}
+	if r.Outcome != Win || r.Winner != First || len(r.Line) != 4 {
+		t.Fatalf("result = %+v, want a vertical win of the first player", r)
+	}
+
+	full := newGame(t, Config{Mode: Gravity, Width: 3, Height: 3, WinLength: 3})
+	play(t, full, at(6, 3, 0)...)
+	if _, err := full.Drop(0); !errors.Is(err, ErrColumnFull) {
+		t.Errorf("Drop into a full column = %v, want %v", err, ErrColumnFull)
+	}
+}
+
+func TestUltimate(t *testing.T) {
+	g := newGame(t, Config{Mode: Ultimate})
+	idx := func(sub, cell int) int {
+		pos, err := UltimateIndex(sub, cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pos
+	}
+
+	// X выигрывает подполе 0 верхней строкой; O каждый раз отправляют в подполе 0
+	play(t, g, at(idx(0, 1), idx(1, 0), idx(0, 2), idx(2, 0))...)
+	if g.Next() != 0 {
+		t.Fatalf("Next() = %d, want 0", g.Next())
+	}
+	play(t, g, at(idx(0, 0))...)
+	if got := g.SubBoards(); got != "X        " {
+		t.Fatalf("SubBoards() = %q", got)
+	}
+
+	// Ход в выигранное подполе даёт сопернику свободный выбор
+	play(t, g, at(idx(4, 0))...)
+	if g.Next() != -1 {
+		t.Errorf("Next() = %d, want -1 after sending into a closed board", g.Next())
+	}
+	if _, err := g.Apply(Move{Position: idx(0, 4)}); !errors.Is(err, ErrBoardClosed) {
+		t.Errorf("move into a decided board = %v, want %v", err, ErrBoardClosed)
+	}
+}
+
+func TestCube(t *testing.T) {
+	g := newGame(t, Config{Mode: Cube})
+	cfg := g.Config()
+	idx := func(layer, row, col int) int {
+		pos, err := cfg.Index3(layer, row, col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pos
+	}
+
+	// X строит главную диагональ куба, O ставит в последний ряд последнего слоя
+	var moves []int
+	for i := 0; i < 4; i++ {
+		moves = append(moves, idx(i, i, i))
+		if i < 3 {
+			moves = append(moves, idx(3, 3, i))
+		}
+	}
+	r := play(t, g, at(moves...)...)
+	if r.Outcome != Win || r.Winner != First || len(r.Line) != 4 {
+		t.Fatalf("result = %+v, want a win along the main diagonal", r)
+	}
+}
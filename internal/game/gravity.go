@@ -0,0 +1,28 @@
+package game
+
+// gravityLayout — фишка падает на самую нижнюю свободную клетку столбца.
+// Линии считаются так же, как на обычном поле.
+type gravityLayout struct {
+	standardLayout
+}
+
+func (gravityLayout) check(g *Game, pos int) error {
+	row, col := g.cfg.Coords(pos)
+	if row+1 < g.cfg.Height && g.markAt(row+1, col) == Empty {
+		return ErrNotLanded
+	}
+	return nil
+}
+
+// Drop возвращает клетку, на которую упадёт фишка, брошенная в столбец
+func (g *Game) Drop(col int) (int, error) {
+	if g.cfg.Mode != Gravity || col < 0 || col >= g.cfg.Width {
+		return 0, ErrInvalidPosition
+	}
+	for row := g.cfg.Height - 1; row >= 0; row-- {
+		if g.markAt(row, col) == Empty {
+			return row*g.cfg.Width + col, nil
+		}
+	}
+	return 0, ErrColumnFull
+}
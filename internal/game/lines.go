@@ -0,0 +1,118 @@
+package game
+
+// Направления линий на плоском поле: горизонталь, вертикаль и две диагонали.
+// Смещения заданы как (слой, строка, столбец).
+var directions = [][3]int{
+	{0, 0, 1},
+	{0, 1, 0},
+	{0, 1, 1},
+	{0, 1, -1},
+}
+
+// Направления линий в кубе: к плоским добавляются вертикаль между слоями,
+// диагонали граней и четыре главные диагонали — всего 13
+var directions3D = append(append([][3]int(nil), directions...),
+	[3]int{1, 0, 0},
+	[3]int{1, 0, 1}, [3]int{1, 0, -1},
+	[3]int{1, 1, 0}, [3]int{1, -1, 0},
+	[3]int{1, 1, 1}, [3]int{1, 1, -1}, [3]int{1, -1, 1}, [3]int{1, -1, -1},
+)
+
+// lineThrough ищет линию из WinLength одинаковых знаков, проходящую через клетку
+func (g *Game) lineThrough(pos int) []int {
+	mark := g.board[pos]
+	if mark == Empty {
+		return nil
+	}
+
+	dirs := directions
+	if g.cfg.Mode == Cube {
+		dirs = directions3D
+	}
+
+	layer, row, col := g.cfg.Coords3(pos)
+	for _, d := range dirs {
+		// Отступаем к началу серии, затем идём вперёд до её конца
+		z, r, c := layer, row, col
+		for g.markAt3(z-d[0], r-d[1], c-d[2]) == mark {
+			z, r, c = z-d[0], r-d[1], c-d[2]
+		}
+
+		var line []int
+		for g.markAt3(z, r, c) == mark {
+			line = append(line, g.cfg.index3(z, r, c))
+			z, r, c = z+d[0], r+d[1], c+d[2]
+		}
+
+		if g.cfg.lineLength(len(line)) {
+			return line
+		}
+	}
+	return nil
+}
+
+// lineLength сообщает, считается ли серия из n знаков линией. В «Порядке
+// и Хаосе» Порядку нужно ровно WinLength в ряд, в остальных правилах
+// засчитывается и более длинная серия.
+func (c Config) lineLength(n int) bool {
+	if c.Rules == OrderChaosRules {
+		return n == c.WinLength
+	}
+	return n >= c.WinLength
+}
+
+// findLine проверяет всё поле на наличие выигрышной линии
+func (g *Game) findLine() []int {
+	for pos := range g.board {
+		if line := g.lineThrough(pos); line != nil {
+			return line
+		}
+	}
+	return nil
+}
+
+// markAt возвращает знак в клетке плоского поля или Empty за его пределами
+func (g *Game) markAt(row, col int) Mark {
+	return g.markAt3(0, row, col)
+}
+
+// markAt3 возвращает знак в клетке (слой, строка, столбец) или Empty за пределами поля
+func (g *Game) markAt3(layer, row, col int) Mark {
+	if layer < 0 || layer >= g.cfg.depth() || row < 0 || row >= g.cfg.Height || col < 0 || col >= g.cfg.Width {
+		return Empty
+	}
+	return g.board[g.cfg.index3(layer, row, col)]
+}
+
+// Windows возвращает все отрезки из WinLength клеток, на которых может
+// образоваться линия. В ультимативном режиме линии строятся из подполей,
+// поэтому отрезков нет и возвращается nil.
+func (c Config) Windows() [][]int {
+	if c.Mode == Ultimate {
+		return nil
+	}
+
+	dirs := directions
+	if c.Mode == Cube {
+		dirs = directions3D
+	}
+
+	var windows [][]int
+	for pos := 0; pos < c.Cells(); pos++ {
+		layer, row, col := c.Coords3(pos)
+		for _, d := range dirs {
+			window := make([]int, 0, c.WinLength)
+			for i := 0; i < c.WinLength; i++ {
+				z, r, x := layer+i*d[0], row+i*d[1], col+i*d[2]
+				if z < 0 || z >= c.depth() || r < 0 || r >= c.Height || x < 0 || x >= c.Width {
+					break
+				}
+				window = append(window, c.index3(z, r, x))
+			}
+			if len(window) == c.WinLength {
+				windows = append(windows, window)
+			}
+		}
+	}
+	return windows
+}
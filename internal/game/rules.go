@@ -0,0 +1,131 @@
+package game
+
+// Rules — название набора правил, как оно хранится в комнате
+type Rules string
+
+const (
+	StandardRules   Rules = "standard"    // X против O, линия побеждает
+	MisereRules     Rules = "misere"      // X против O, линия проигрывает
+	WildRules       Rules = "wild"        // Каждый ставит X или O, линия побеждает
+	NotaktoRules    Rules = "notakto"     // Оба ставят X, линия проигрывает
+	OrderChaosRules Rules = "order_chaos" // Порядок (First) строит линию, Хаос (Second) мешает
+)
+
+// ParseRules разбирает название правил; пустая строка — стандартные правила
+func ParseRules(s string) (Rules, error) {
+	switch Rules(s) {
+	case "":
+		return StandardRules, nil
+	case StandardRules, MisereRules, WildRules, NotaktoRules, OrderChaosRules:
+		return Rules(s), nil
+	}
+	return "", ErrInvalidConfig
+}
+
+// RuleSet — правила, определяющие, кто какие знаки ставит и кто побеждает
+type RuleSet interface {
+	// Marks возвращает знаки, которые может поставить игрок
+	Marks(p Player) []Mark
+	// Judge подводит итог после хода игрока mover по найденным на поле линиям
+	Judge(mover Player, l Lines) Result
+}
+
+// RuleSet возвращает реализацию правил; неизвестное название — стандартные правила
+func (r Rules) RuleSet() RuleSet {
+	switch r {
+	case MisereRules:
+		return misere{}
+	case WildRules:
+		return wild{}
+	case NotaktoRules:
+		return notakto{}
+	case OrderChaosRules:
+		return orderChaos{}
+	}
+	return standard{}
+}
+
+// ruleSet возвращает правила партии с учётом того, каким знаком играет первый игрок
+func (c Config) ruleSet() RuleSet {
+	rs := c.Rules.RuleSet()
+	if c.FirstMark == O {
+		return swapped{rs}
+	}
+	return rs
+}
+
+// swapped — правила, в которых игроки поменялись знаками. Итог партии
+// определяется по тому, кто ходил, а не по знаку, поэтому Judge не меняется.
+type swapped struct{ RuleSet }
+
+func (s swapped) Marks(p Player) []Mark { return s.RuleSet.Marks(p.Other()) }
+
+var (
+	onlyX = []Mark{X}
+	onlyO = []Mark{O}
+	both  = []Mark{X, O}
+)
+
+// ownMarks — у первого игрока X, у второго O
+func ownMarks(p Player) []Mark {
+	if p == First {
+		return onlyX
+	}
+	return onlyO
+}
+
+// lineWins — кто построил линию, тот и выиграл
+func lineWins(mover Player, l Lines) Result {
+	switch {
+	case l.Line != nil:
+		return Result{Outcome: Win, Winner: mover, Line: l.Line}
+	case l.Full:
+		return Result{Outcome: Draw}
+	}
+	return Result{Outcome: Ongoing}
+}
+
+// lineLoses — кто построил линию, тот и проиграл
+func lineLoses(mover Player, l Lines) Result {
+	r := lineWins(mover, l)
+	if r.Outcome == Win {
+		r.Winner = mover.Other()
+	}
+	return r
+}
+
+type standard struct{}
+
+func (standard) Marks(p Player) []Mark              { return ownMarks(p) }
+func (standard) Judge(mover Player, l Lines) Result { return lineWins(mover, l) }
+
+type misere struct{}
+
+func (misere) Marks(p Player) []Mark              { return ownMarks(p) }
+func (misere) Judge(mover Player, l Lines) Result { return lineLoses(mover, l) }
+
+type wild struct{}
+
+func (wild) Marks(Player) []Mark                { return both }
+func (wild) Judge(mover Player, l Lines) Result { return lineWins(mover, l) }
+
+type notakto struct{}
+
+func (notakto) Marks(Player) []Mark                { return onlyX }
+func (notakto) Judge(mover Player, l Lines) Result { return lineLoses(mover, l) }
+
+// orderChaos — любая линия ровно из WinLength знаков, кем бы она ни была построена,
+// приносит победу Порядку; если поле заполнено без линии, побеждает Хаос
+type orderChaos struct{}
+
+func (orderChaos) Marks(Player) []Mark { return both }
+
+func (orderChaos) Judge(_ Player, l Lines) Result {
+	switch {
+	case l.Line != nil:
+		return Result{Outcome: Win, Winner: First, Line: l.Line}
+	case l.Full:
+		return Result{Outcome: Win, Winner: Second}
+	}
+	return Result{Outcome: Ongoing}
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRuleSets(t *testing.T) {
+	x := func(pos int) Move { return Move{Position: pos, Mark: X} }
+	o := func(pos int) Move { return Move{Position: pos, Mark: O} }
+
+	tests := []struct {
+		name   string
+		cfg    Config
+		moves  []Move
+		want   Outcome
+		winner Player
+		line   []int
+	}{
+		{"standard", Classic(), at(0, 3, 1, 4, 2), Win, First, []int{0, 1, 2}},
+		{"misere line loses", Config{Rules: MisereRules}, at(0, 3, 1, 4, 2), Win, Second, []int{0, 1, 2}},
+		{"wild line of O by first", Config{Rules: WildRules}, []Move{o(0), x(4), o(1), x(8), o(2)}, Win, First, []int{0, 1, 2}},
+		{"notakto line loses", Config{Rules: NotaktoRules}, at(0, 1, 3, 4, 8), Win, Second, []int{0, 4, 8}},
+		{"first plays O", Config{FirstMark: O}, at(0, 3, 1, 4, 2), Win, First, []int{0, 1, 2}},
+		{"order builds five", Config{Rules: OrderChaosRules}, []Move{x(0), o(5), x(1), o(13), x(2), o(27), x(3), o(32), x(4)}, Win, First, []int{0, 1, 2, 3, 4}},
+		{"chaos completes five", Config{Rules: OrderChaosRules}, []Move{o(6), x(0), o(7), x(13), o(8), x(27), o(9), x(32), o(35), o(10)}, Win, First, []int{6, 7, 8, 9, 10}},
+		{"overline is not a win", Config{Rules: OrderChaosRules}, []Move{x(0), o(13), x(1), o(27), x(2), o(32), x(3), o(16), x(5), o(35), x(4)}, Ongoing, First, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := play(t, newGame(t, tt.cfg), tt.moves...)
+			if r.Outcome != tt.want {
+				t.Fatalf("outcome = %s, want %s", r.Outcome, tt.want)
+			}
+			if r.Outcome == Win && (r.Winner != tt.winner || !reflect.DeepEqual(r.Line, tt.line)) {
+				t.Errorf("winner %d line %v, want %d line %v", r.Winner, r.Line, tt.winner, tt.line)
+			}
+		})
+	}
+}
+
+func TestMarks(t *testing.T) {
+	tests := []struct {
+		cfg           Config
+		first, second []Mark
+	}{
+		{Classic(), []Mark{X}, []Mark{O}},
+		{Config{FirstMark: O}, []Mark{O}, []Mark{X}},
+		{Config{Rules: WildRules}, []Mark{X, O}, []Mark{X, O}},
+		{Config{Rules: NotaktoRules}, []Mark{X}, []Mark{X}},
+		{Config{Rules: OrderChaosRules}, []Mark{X, O}, []Mark{X, O}},
+	}
+	for _, tt := range tests {
+		g := newGame(t, tt.cfg)
+		if got := g.Marks(First); !reflect.DeepEqual(got, tt.first) {
+			t.Errorf("%s: Marks(First) = %v, want %v", tt.cfg.Rules, got, tt.first)
+		}
+		if got := g.Marks(Second); !reflect.DeepEqual(got, tt.second) {
+			t.Errorf("%s: Marks(Second) = %v, want %v", tt.cfg.Rules, got, tt.second)
+		}
+	}
+}
+
+func TestOrderChaosFullBoard(t *testing.T) {
+	// Поле заполнено сериями не длиннее двух — линий нет, побеждает Хаос
+	rows := []string{"XXOOXX", "OOXXOO", "XXOOXX", "OOXXOO", "XXOOXX", "OOXXOO"}
+	g, err := Parse(Config{Rules: OrderChaosRules}.WithDefaults(), State{Board: strings.Join(rows, ""), Turn: First, Next: -1})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if r := g.Result(); r.Outcome != Win || r.Winner != Second {
+		t.Errorf("result = %+v, want a win of chaos", r)
+	}
+
+	// Шесть в ряд при заполненном поле — тоже победа Хаоса
+	rows[0] = "XXXXXX"
+	g, err = Parse(Config{Rules: OrderChaosRules}.WithDefaults(), State{Board: strings.Join(rows, ""), Turn: First, Next: -1})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if r := g.Result(); r.Outcome != Win || r.Winner != Second {
+		t.Errorf("result with an overline = %+v, want a win of chaos", r)
+	}
+}
@@ -0,0 +1,55 @@
+package game
+
+// Symmetries возвращает перестановки клеток, соответствующие поворотам и
+// отражениям поля: perm[i] — клетка исходного поля, которая переходит в клетку i.
+// Симметрии учитываются только у квадратного плоского поля в стандартном
+// режиме; для остальных возвращается лишь тождественная перестановка.
+func (c Config) Symmetries() [][]int {
+	identity := make([]int, c.Cells())
+	for i := range identity {
+		identity[i] = i
+	}
+	if c.Mode != Standard || c.Width != c.Height || c.depth() != 1 {
+		return [][]int{identity}
+	}
+
+	n := c.Width
+	transforms := []func(r, col int) (int, int){
+		func(r, col int) (int, int) { return r, col },
+		func(r, col int) (int, int) { return col, n - 1 - r },
+		func(r, col int) (int, int) { return n - 1 - r, n - 1 - col },
+		func(r, col int) (int, int) { return n - 1 - col, r },
+		func(r, col int) (int, int) { return r, n - 1 - col },
+		func(r, col int) (int, int) { return n - 1 - r, col },
+		func(r, col int) (int, int) { return col, r },
+		func(r, col int) (int, int) { return n - 1 - col, n - 1 - r },
+	}
+
+	perms := make([][]int, len(transforms))
+	for t, f := range transforms {
+		perm := make([]int, c.Cells())
+		for i := range perm {
+			r, col := f(i/n, i%n)
+			perm[i] = r*n + col
+		}
+		perms[t] = perm
+	}
+	return perms
+}
+
+// Canonical возвращает наименьшую из симметричных записей поля и перестановку,
+// которая её даёт: canonical[i] == board[perm[i]]
+func (c Config) Canonical(board string) (string, []int) {
+	var best string
+	var bestPerm []int
+	buf := make([]byte, len(board))
+	for _, perm := range c.Symmetries() {
+		for i, p := range perm {
+			buf[i] = board[p]
+		}
+		if s := string(buf); bestPerm == nil || s < best {
+			best, bestPerm = s, perm
+		}
+	}
+	return best, bestPerm
+}
@@ -0,0 +1,102 @@
+package game
+
+// Выигрышные тройки поля 3x3: используются и для подполей, и для общего поля
+var triples = [][]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8}, // горизонтальные линии
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8}, // вертикальные линии
+	{0, 4, 8}, {2, 4, 6}, // диагонали
+}
+
+// Метка подполя, в котором не осталось ходов, а победителя нет
+const drawnBoard = '-'
+
+// ultimateLayout — поле 3x3 из подполей 3x3. Клетка, в которую сделан ход,
+// определяет подполе, в котором обязан ходить соперник. Если это подполе
+// уже выиграно или заполнено, соперник ходит в любое открытое подполе.
+type ultimateLayout struct{}
+
+func (ultimateLayout) check(g *Game, pos int) error {
+	sub := pos / 9
+	if g.next != -1 && sub != g.next {
+		return ErrWrongBoard
+	}
+	if _, closed := g.subResult(sub); closed {
+		return ErrBoardClosed
+	}
+	return nil
+}
+
+func (l ultimateLayout) played(g *Game, pos int) Lines {
+	g.next = pos % 9
+	if _, closed := g.subResult(g.next); closed {
+		g.next = -1
+	}
+	return l.evaluate(g)
+}
+
+// Линия на общем поле — три подполя в ряд, выигранных одним знаком.
+// В Lines.Line (а значит, и в Result.Line) в этом режиме лежат номера подполей.
+func (ultimateLayout) evaluate(g *Game) Lines {
+	var winners [9]Mark
+	open := false
+	for sub := range winners {
+		winner, closed := g.subResult(sub)
+		winners[sub] = winner
+		if !closed {
+			open = true
+		}
+	}
+
+	for _, t := range triples {
+		first := winners[t[0]]
+		if first != Empty && first == winners[t[1]] && first == winners[t[2]] {
+			return Lines{Line: append([]int(nil), t...), Mark: first, Full: !open}
+		}
+	}
+	return Lines{Full: !open}
+}
+
+// subResult возвращает победителя подполя и признак того, что в нём больше нельзя ходить
+func (g *Game) subResult(sub int) (Mark, bool) {
+	cells := g.board[sub*9 : sub*9+9]
+	for _, t := range triples {
+		first := cells[t[0]]
+		if first != Empty && first == cells[t[1]] && first == cells[t[2]] {
+			return first, true
+		}
+	}
+	for _, m := range cells {
+		if m == Empty {
+			return Empty, false
+		}
+	}
+	return Empty, true
+}
+
+// Next возвращает подполе, в котором обязан ходить игрок, или -1, если ход свободный
+func (g *Game) Next() int {
+	return g.next
+}
+
+// SubBoards возвращает итоги подполей строкой из девяти символов:
+// X или O — подполе выиграно, '-' — ничья, пробел — игра продолжается.
+// Для остальных режимов возвращается пустая строка.
+func (g *Game) SubBoards() string {
+	if g.cfg.Mode != Ultimate {
+		return ""
+	}
+
+	b := make([]byte, 9)
+	for sub := range b {
+		winner, closed := g.subResult(sub)
+		switch {
+		case winner != Empty:
+			b[sub] = byte(winner)
+		case closed:
+			b[sub] = drawnBoard
+		default:
+			b[sub] = byte(Empty)
+		}
+	}
+	return string(b)
+}
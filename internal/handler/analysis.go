@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"tic_tac_toe/internal/bot"
+)
+
+// moveEvaluation — оценка хода в ответе анализа
+type moveEvaluation struct {
+	Position int    `json:"position"`
+	Mark     string `json:"mark"`
+	Value    string `json:"value"`           // win, draw, loss или unknown для того, кто ходит
+	Plies    int    `json:"plies,omitempty"` // Полуходов до конца партии при победе или поражении
+}
+
+// analyseRoom оценивает все допустимые ходы в текущей позиции комнаты
+func analyseRoom(ctx context.Context, cfg bot.Config, roomInfo map[string]string) (map[string]interface{}, error) {
+	g, err := loadGame(roomInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	solver := &bot.AlphaBeta{Budget: cfg.ThinkTime}
+	evals, err := solver.Solve(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+
+	moves := make([]moveEvaluation, len(evals))
+	for i, e := range evals {
+		moves[i] = moveEvaluation{
+			Position: e.Move.Position,
+			Mark:     e.Move.Mark.String(),
+			Value:    string(e.Value),
+			Plies:    e.Plies,
+		}
+	}
+
+	return map[string]interface{}{
+		"board": roomInfo["board"],
+		"turn":  roomInfo["turn"],
+		"moves": moves,
+	}, nil
+}
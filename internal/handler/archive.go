@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"tic_tac_toe/internal/archive"
+	"tic_tac_toe/internal/repository"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// gameRecord собирает архивную запись законченной партии из данных комнаты
+func gameRecord(roomID string, roomInfo map[string]string, history []repository.Move, finishedAt time.Time) archive.Game {
+	atoi := func(field string) int {
+		n, _ := strconv.Atoi(roomInfo[field])
+		return n
+	}
+	first, _ := seats(roomInfo)
+
+	g := archive.Game{
+		ID:         roomInfo["game_id"],
+		RoomID:     roomID,
+		Player1:    roomInfo["user1"],
+		Player2:    roomInfo["user2"],
+		First:      first,
+		Mode:       roomInfo["mode"],
+		Rules:      roomInfo["rules"],
+		Width:      atoi("width"),
+		Height:     atoi("height"),
+		Depth:      atoi("depth"),
+		WinLength:  atoi("win_length"),
+		Status:     roomInfo["status"],
+		Winner:     roomInfo["winner"],
+		Reason:     roomInfo["reason"],
+		Board:      roomInfo["board"],
+		FinishedAt: finishedAt,
+		Moves:      make([]archive.Move, len(history)),
+	}
+	// Комнаты, созданные до появления game_id, хранят одну партию за раз
+	if g.ID == "" {
+		g.ID = roomID
+	}
+
+	// Начало партии — её старт, для старых комнат — первый ход
+	switch {
+	case roomInfo["started_at"] != "":
+		g.StartedAt = time.UnixMilli(int64(atoi("started_at")))
+	case len(history) > 0:
+		g.StartedAt = history[0].Time
+	default:
+		g.StartedAt = finishedAt
+	}
+
+	for i, m := range history {
+		g.Moves[i] = archive.Move{
+			Seq:      m.Seq,
+			Player:   m.Player,
+			Position: m.Position,
+			Mark:     m.Mark,
+			PlayedAt: m.Time,
+		}
+	}
+	return g
+}
+
+// archiveGame записывает законченную партию в архив, если он включён
+func (h *WebSocketHandler) archiveGame(ctx context.Context, roomID string) error {
+	if h.Archive == nil {
+		return nil
+	}
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch room info: %w", err)
+	}
+	if !gameFinished(roomInfo) {
+		return nil
+	}
+	history, err := h.Repo.GetMoves(roomID)
+	if err != nil {
+		return err
+	}
+	return h.Archive.Save(ctx, gameRecord(roomID, roomInfo, history, time.Now()))
+}
+
+// parseDay разбирает момент времени: RFC 3339 или дату. Дата означает начало
+// дня, а для конца периода — начало следующего, чтобы день вошёл целиком.
+func parseDay(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return t, fmt.Errorf("invalid date %q", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// Найти партии в архиве по игроку и периоду: /games?player=...&from=...&to=...
+func (h *RoomHandler) ListGames(c echo.Context) error {
+	if h.Archive == nil {
+		return h.respond(c, http.StatusServiceUnavailable, map[string]string{"error": "Game archive is disabled"})
+	}
+
+	q := archive.Query{Player: c.QueryParam("player")}
+	var err error
+	if q.From, err = parseDay(c.QueryParam("from"), false); err != nil {
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if q.To, err = parseDay(c.QueryParam("to"), true); err != nil {
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	for param, dst := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		value := c.QueryParam(param)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return h.respond(c, http.StatusBadRequest, map[string]string{"error": "Invalid " + param})
+		}
+		*dst = n
+	}
+	q.Limit = min(q.Limit, 200)
+
+	games, err := h.Archive.Find(c.Request().Context(), q)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to find games: "+err.Error())
+		return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to find games"})
+	}
+	return h.respond(c, http.StatusOK, map[string]interface{}{"games": games})
+}
+
+// Получить архивную партию вместе с ходами
+func (h *RoomHandler) GetGame(c echo.Context) error {
+	if h.Archive == nil {
+		return h.respond(c, http.StatusServiceUnavailable, map[string]string{"error": "Game archive is disabled"})
+	}
+
+	g, err := h.Archive.Get(c.Request().Context(), c.Param("game_id"))
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to get game: "+err.Error())
+		return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to get game"})
+	}
+	if g == nil {
+		return h.respond(c, http.StatusNotFound, map[string]string{"error": "Game not found"})
+	}
+	return h.respond(c, http.StatusOK, g)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/repository"
+)
+
+// playBot выбирает ход за бота и проводит его через processMove,
+// так что проверки и рассылка обновлений те же, что и для людей
+func (h *WebSocketHandler) playBot(roomID string) {
+	ctx := context.Background()
+
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Bot failed to fetch room %s: %s", roomID, err.Error()))
+		return
+	}
+	if roomInfo["turn"] != bot.Name {
+		return
+	}
+
+	g, err := loadGame(roomInfo)
+	if err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Bot failed to restore game in room %s: %s", roomID, err.Error()))
+		return
+	}
+
+	level, err := bot.ParseLevel(roomInfo["bot_level"])
+	if err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Bot has invalid level in room %s: %s", roomID, err.Error()))
+		return
+	}
+
+	move, err := h.Bots.Engine(roomID, g.Config(), level).BestMove(ctx, g)
+	if err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Bot failed to choose a move in room %s: %s", roomID, err.Error()))
+		return
+	}
+
+	data := map[string]string{
+		"position": strconv.Itoa(move.Position),
+		"mark":     move.Mark.String(),
+	}
+	if err := h.processMove(ctx, roomID, bot.Name, data); err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Bot move error in room %s: %s", roomID, err.Error()))
+		// Комнату изменили, пока бот думал: его ход просто устарел
+		if errors.Is(err, repository.ErrConflict) {
+			return
+		}
+		h.BroadcastMessage(ctx, roomID, "error", map[string]string{
+			"message": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"tic_tac_toe/internal/clock"
+	"time"
+)
+
+// clockSide возвращает место пользователя на часах: 0 — user1, 1 — user2
+func clockSide(roomInfo map[string]string, user string) int {
+	if user != "" && user == roomInfo["user2"] {
+		return 1
+	}
+	return 0
+}
+
+// clockUser возвращает пользователя по месту на часах
+func clockUser(roomInfo map[string]string, side int) string {
+	if side == 1 {
+		return roomInfo["user2"]
+	}
+	return roomInfo["user1"]
+}
+
+// readClock восстанавливает часы из данных комнаты. Время хранится
+// в миллисекундах, момент запуска — как Unix-время в миллисекундах.
+func readClock(roomInfo map[string]string) (clock.Clock, error) {
+	kind, err := clock.ParseKind(roomInfo["clock_kind"])
+	if err != nil {
+		return clock.Clock{}, err
+	}
+	ms := func(field string) (int64, error) {
+		if roomInfo[field] == "" {
+			return 0, nil
+		}
+		return strconv.ParseInt(roomInfo[field], 10, 64)
+	}
+
+	var values [6]int64
+	for i, field := range []string{"clock_initial", "clock_increment", "clock1", "clock2", "clock_running", "clock_since"} {
+		if values[i], err = ms(field); err != nil {
+			return clock.Clock{}, fmt.Errorf("invalid %s: %w", field, err)
+		}
+	}
+
+	c := clock.New(clock.Control{
+		Kind:      kind,
+		Initial:   time.Duration(values[0]) * time.Millisecond,
+		Increment: time.Duration(values[1]) * time.Millisecond,
+	})
+	if roomInfo["clock1"] != "" {
+		c.Remaining = [2]time.Duration{time.Duration(values[2]) * time.Millisecond, time.Duration(values[3]) * time.Millisecond}
+	}
+	// Идущие часы хранятся как номер места с единицы: 0 — часы стоят
+	c.Running = int(values[4]) - 1
+	c.Since = time.UnixMilli(values[5])
+	return c, nil
+}
+
+// clockFields возвращает поля комнаты, в которых хранятся часы;
+// у партии без часов таких полей нет
+func clockFields(c clock.Clock) map[string]interface{} {
+	if !c.Enabled() {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"clock_kind":      string(c.Kind),
+		"clock_initial":   c.Initial.Milliseconds(),
+		"clock_increment": c.Increment.Milliseconds(),
+		"clock1":          c.Remaining[0].Milliseconds(),
+		"clock2":          c.Remaining[1].Milliseconds(),
+		"clock_running":   c.Running + 1,
+		"clock_since":     c.Since.UnixMilli(),
+	}
+}
+
+// clockView — показания часов для клиентов: остаток каждого игрока на момент
+// server_time и чьё время идёт. nil, если партия без часов.
+func clockView(roomInfo map[string]string, now time.Time) map[string]interface{} {
+	c, err := readClock(roomInfo)
+	if err != nil || !c.Enabled() {
+		return nil
+	}
+	running := ""
+	if c.Running >= 0 {
+		running = clockUser(roomInfo, c.Running)
+	}
+	return map[string]interface{}{
+		"kind":      c.Kind,
+		"increment": c.Increment.Milliseconds(),
+		"remaining": map[string]int64{
+			roomInfo["user1"]: c.Left(0, now).Milliseconds(),
+			roomInfo["user2"]: c.Left(1, now).Milliseconds(),
+		},
+		"running":     running,
+		"server_time": now.UnixMilli(),
+	}
+}
+
+// withClock дополняет данные комнаты показаниями часов
+func withClock(roomInfo map[string]string) map[string]interface{} {
+	return withFields(roomInfo, map[string]interface{}{"clock": clockView(roomInfo, time.Now())})
+}
+
+// flagFall засчитывает поражение игроку, чьё время кончилось
+func (h *WebSocketHandler) flagFall(ctx context.Context, roomID string, roomInfo map[string]string, c clock.Clock) error {
+	loser := clockUser(roomInfo, c.Running)
+	return h.finish(ctx, roomID, roomInfo, "finished", h.getNextPlayer(roomInfo, loser), ReasonTimeout)
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/repository"
+	"time"
+)
+
+// Как часто сервер проверяет наступившие сроки
+const deadlinePoll = 100 * time.Millisecond
+
+// На сколько откладывается захваченный срок. Если за это время ход за игрока
+// так и не сделан — поиск хода не удался или сервер упал, — срок наступит снова.
+const deadlineLease = 10 * time.Second
+
+// Что делает сервер, если игрок не уложился во время на ход
+const (
+	TimeoutForfeit = "forfeit" // Игрок проигрывает
+	TimeoutRandom  = "random"  // За игрока делается случайный ход
+	TimeoutBot     = "bot"     // За игрока ходит бот
+)
+
+// moveLimit возвращает время на ход в комнате, 0 — без ограничения
+func moveLimit(roomInfo map[string]string) time.Duration {
+	ms, _ := strconv.ParseInt(roomInfo["move_limit"], 10, 64)
+	return time.Duration(ms) * time.Millisecond
+}
+
+// moveDeadline возвращает, до какого момента должен походить игрок, чья очередь;
+// нулевое время, если срока нет
+func moveDeadline(roomInfo map[string]string) time.Time {
+	ms, _ := strconv.ParseInt(roomInfo["move_deadline"], 10, 64)
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// nextMoveDeadline возвращает значение поля move_deadline для хода пользователя turn,
+// начатого в момент now. Бот ходит сам, поэтому срок ему не назначается.
+func nextMoveDeadline(roomInfo map[string]string, turn string, now time.Time) int64 {
+	limit := moveLimit(roomInfo)
+	if limit == 0 || turn == bot.Name {
+		return 0
+	}
+	return now.Add(limit).UnixMilli()
+}
+
+// roomDeadline возвращает ближайший из сроков комнаты — падение флага или
+// конец времени на ход; нулевое время, если партия не идёт
+func roomDeadline(roomInfo map[string]string) time.Time {
+	if !gameActive(roomInfo) {
+		return time.Time{}
+	}
+	deadline := moveDeadline(roomInfo)
+	if c, err := readClock(roomInfo); err == nil {
+		if flag := c.Deadline(); !flag.IsZero() && (deadline.IsZero() || flag.Before(deadline)) {
+			deadline = flag
+		}
+	}
+	return deadline
+}
+
+// afterUpdate возвращает, какой станет комната roomInfo после записи полей fields
+func afterUpdate(roomInfo map[string]string, fields map[string]interface{}) map[string]string {
+	updated := make(map[string]string, len(roomInfo)+len(fields))
+	for field, value := range roomInfo {
+		updated[field] = value
+	}
+	for field, value := range fields {
+		updated[field] = fmt.Sprint(value)
+	}
+	return updated
+}
+
+// withDeadline добавляет к изменению комнаты её новый срок. Срок пишется той же
+// записью, что и поля партии: иначе падение сервера между двумя записями
+// оставило бы комнату со сроком, который никто не проверит.
+func withDeadline(u repository.RoomUpdate, roomInfo map[string]string) repository.RoomUpdate {
+	u.SetDeadline = true
+	u.Deadline = roomDeadline(afterUpdate(roomInfo, u.Fields))
+	return u
+}
+
+// scheduleDeadline заново назначает комнате срок по её текущему состоянию.
+// Нужна, только когда проверка срока застала партию уже ушедшей дальше.
+func scheduleDeadline(repo repository.RoomStore, roomID string, roomInfo map[string]string) error {
+	deadline := roomDeadline(roomInfo)
+	if deadline.IsZero() {
+		_, err := repo.CancelDeadline(roomID)
+		return err
+	}
+	return repo.ScheduleDeadline(roomID, deadline)
+}
+
+// WatchDeadlines проверяет наступившие сроки комнат, пока не отменён ctx.
+// Сроки хранятся в Redis, поэтому переживают перезапуск сервера, а флаг
+// падает, даже если игрок отключился и больше ничего не присылает.
+func (h *WebSocketHandler) WatchDeadlines(ctx context.Context) {
+	ticker := time.NewTicker(deadlinePoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			rooms, err := h.Repo.DueDeadlines(now)
+			if err != nil {
+				h.Logger.Error(ctx, err.Error())
+				continue
+			}
+			for _, roomID := range rooms {
+				// Срок обрабатывает тот экземпляр сервера, который первым его захватил.
+				// Снимется он, только когда партия пойдёт дальше: запись хода или
+				// окончания партии назначит следующий срок или снимет его.
+				claimed, err := h.Repo.ClaimDeadline(roomID, now, deadlineLease)
+				if err != nil {
+					h.Logger.Error(ctx, err.Error())
+					continue
+				}
+				if claimed {
+					if err := h.expire(ctx, roomID); err != nil {
+						h.Logger.Error(ctx, fmt.Sprintf("Failed to check deadline in room %s: %s", roomID, err.Error()))
+					}
+				}
+			}
+		}
+	}
+}
+
+// expire обрабатывает наступивший срок: флаг упал или кончилось время на ход
+func (h *WebSocketHandler) expire(ctx context.Context, roomID string) error {
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if errors.Is(err, repository.ErrRoomNotFound) {
+		_, err = h.Repo.CancelDeadline(roomID)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if !gameActive(roomInfo) {
+		return scheduleDeadline(h.Repo, roomID, roomInfo)
+	}
+	c, err := readClock(roomInfo)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if c.Flagged(now) {
+		return h.flagFall(ctx, roomID, roomInfo, c)
+	}
+	if deadline := moveDeadline(roomInfo); !deadline.IsZero() && !now.Before(deadline) {
+		return h.moveTimeout(ctx, roomID, roomInfo)
+	}
+
+	// Ход мог быть сделан уже после того, как срок был найден
+	return scheduleDeadline(h.Repo, roomID, roomInfo)
+}
+
+// moveTimeout поступает с не успевшим походить игроком так, как настроено в комнате
+func (h *WebSocketHandler) moveTimeout(ctx context.Context, roomID string, roomInfo map[string]string) error {
+	player := roomInfo["turn"]
+	if roomInfo["move_timeout"] != TimeoutRandom && roomInfo["move_timeout"] != TimeoutBot {
+		return h.finish(ctx, roomID, roomInfo, "finished", h.getNextPlayer(roomInfo, player), ReasonTimeout)
+	}
+
+	// Поиск хода может занять время, а проверка сроков не должна ждать
+	go h.autoMove(roomID, roomInfo, player)
+	return nil
+}
+
+// autoMove ходит за игрока, не успевшего походить: случайно или как бот.
+// Если ход сделать не удалось, срок наступит снова по истечении deadlineLease.
+func (h *WebSocketHandler) autoMove(roomID string, roomInfo map[string]string, player string) {
+	ctx := context.Background()
+
+	g, err := loadGame(roomInfo)
+	if err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Failed to restore game in room %s: %s", roomID, err.Error()))
+		return
+	}
+	moves := g.LegalMoves()
+	if len(moves) == 0 {
+		return
+	}
+
+	move := moves[rand.IntN(len(moves))]
+	if roomInfo["move_timeout"] == TimeoutBot {
+		level, _ := bot.ParseLevel(roomInfo["bot_level"])
+		engine := bot.New(h.Bots.Config(), g.Config(), level, bot.Seed(roomID+player), nil)
+		if move, err = engine.BestMove(ctx, g); err != nil {
+			h.Logger.Error(ctx, fmt.Sprintf("Failed to choose a move in room %s: %s", roomID, err.Error()))
+			return
+		}
+	}
+
+	data := map[string]string{
+		"position": strconv.Itoa(move.Position),
+		"mark":     move.Mark.String(),
+	}
+	if err := h.playMove(ctx, roomID, player, data, true); err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Automatic move error in room %s: %s", roomID, err.Error()))
+		return
+	}
+	h.BroadcastMessage(ctx, roomID, "auto_move", map[string]interface{}{
+		"player":   player,
+		"position": move.Position,
+	})
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"tic_tac_toe/internal/repository"
+	"time"
+)
+
+// Как часто сервер ищет комнаты с истёкшим сроком жизни
+const expiryPoll = time.Second
+
+// Пауза перед повторной подпиской на удалённые комнаты: растёт вдвое после
+// каждой неудачи, но не больше expiryRetryMax
+const (
+	expiryRetryMin = time.Second
+	expiryRetryMax = time.Minute
+)
+
+// WatchExpiries удаляет комнаты, в которых слишком долго ничего не происходит,
+// пока не отменён ctx. Срок жизни зависит от состояния комнаты и продлевается
+// каждым её изменением и сообщением игрока. Об удалении узнаёт каждый экземпляр
+// сервера и предупреждает подключённых к нему игроков.
+func (h *WebSocketHandler) WatchExpiries(ctx context.Context) {
+	go h.watchExpired(ctx)
+
+	// Комнаты, созданные до появления сроков жизни, иначе не удалились бы никогда
+	if n, err := h.Repo.BackfillExpiries(); err != nil {
+		h.Logger.Error(ctx, "Failed to backfill room expiries: "+err.Error())
+	} else if n > 0 {
+		h.Logger.Info(ctx, fmt.Sprintf("Scheduled expiry of %d rooms", n))
+	}
+
+	ticker := time.NewTicker(expiryPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			rooms, err := h.Repo.DueExpiries(now)
+			if err != nil {
+				h.Logger.Error(ctx, err.Error())
+				continue
+			}
+			for _, roomID := range rooms {
+				// Комнату удаляет тот экземпляр сервера, который успел первым, и только
+				// если её не продлили ходом или сообщением после DueExpiries
+				expired, err := h.Repo.ExpireRoom(roomID, now)
+				if err != nil {
+					h.Logger.Error(ctx, fmt.Sprintf("Failed to expire room %s: %s", roomID, err.Error()))
+					continue
+				}
+				if expired {
+					h.Logger.Info(ctx, fmt.Sprintf("Room %s expired", roomID))
+				}
+			}
+		}
+	}
+}
+
+// watchExpired получает удалённые по сроку комнаты, пока не отменён ctx.
+// Подписка, которая не удалась или оборвалась, повторяется: без неё экземпляр
+// сервера не предупредит своих игроков и не забудет их соединения.
+func (h *WebSocketHandler) watchExpired(ctx context.Context) {
+	wait := expiryRetryMin
+	for {
+		started := time.Now()
+		err := h.Repo.WatchExpired(ctx, func(roomID string, state repository.RoomState) {
+			h.roomExpired(ctx, roomID, state)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			h.Logger.Error(ctx, err.Error())
+		}
+		// Подписка, проработавшая долго, оборвалась, а не падает раз за разом
+		if time.Since(started) > expiryRetryMax {
+			wait = expiryRetryMin
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, expiryRetryMax)
+	}
+}
+
+// roomExpired освобождает бота удалённой по сроку комнаты, предупреждает
+// подключённых к ней игроков и закрывает их соединения
+func (h *WebSocketHandler) roomExpired(ctx context.Context, roomID string, state repository.RoomState) {
+	h.Bots.Release(roomID)
+	h.BroadcastMessage(ctx, roomID, "room_expired", map[string]interface{}{
+		"roomID": roomID,
+		"state":  state,
+	})
+	h.closeRoom(roomID)
+}
+
+// closeRoom закрывает все соединения комнаты и забывает о них. Циклы чтения
+// этих соединений завершатся сами, не найдя комнату в Clients.
+func (h *WebSocketHandler) closeRoom(roomID string) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	for _, conn := range h.Clients[roomID] {
+		conn.Close()
+	}
+	delete(h.Clients, roomID)
+}
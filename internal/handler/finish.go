@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/game"
+	"tic_tac_toe/internal/repository"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Причины окончания партии, которые пишутся в поле reason комнаты
+const (
+	ReasonResignation = "resignation" // Игрок сдался
+	ReasonAgreement   = "agreement"   // Ничья по соглашению
+	ReasonLine        = "line"        // Собрана линия
+	ReasonBoardFull   = "board_full"  // Поле заполнено
+	ReasonTimeout     = "timeout"     // У игрока кончилось время
+)
+
+// resultReason объясняет, чем закончилась партия на доске
+func resultReason(r game.Result) string {
+	if r.Outcome == game.Win && len(r.Line) > 0 {
+		return ReasonLine
+	}
+	return ReasonBoardFull
+}
+
+// gameActive сообщает, что партия идёт и её можно закончить досрочно
+func gameActive(roomInfo map[string]string) bool {
+	return roomInfo["status"] == "started" || roomInfo["status"] == "ongoing"
+}
+
+// processFinish обрабатывает досрочное окончание партии: resign, offer_draw,
+// accept_draw и decline_draw. Предложение ничьей хранится в поле draw_offer
+// до ответа соперника или до следующего хода. Ответ бота на предложение
+// ничьей ищется в фоне и уходит через conn.
+func (h *WebSocketHandler) processFinish(ctx context.Context, roomID, player, action string, conn *websocket.Conn) error {
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch room info: %w", err)
+	}
+	if player == "" || (player != roomInfo["user1"] && player != roomInfo["user2"]) {
+		return fmt.Errorf("you are not a player in this room")
+	}
+	if !gameActive(roomInfo) {
+		return game.ErrGameOver
+	}
+	offer := roomInfo["draw_offer"]
+	opponent := h.getNextPlayer(roomInfo, player)
+
+	switch action {
+	case "resign":
+		return h.finish(ctx, roomID, roomInfo, "finished", opponent, ReasonResignation)
+
+	case "offer_draw":
+		if offer != "" {
+			return fmt.Errorf("draw already offered")
+		}
+		if opponent == bot.Name {
+			// Бот думает до BOT_THINK_TIME, цикл чтения соединения тем временем не ждёт
+			go h.processBotDraw(ctx, roomID, roomInfo, player, conn)
+			return nil
+		}
+		if _, err := h.Repo.UpdateRoom(roomID, repository.RoomUpdate{
+			Version: roomInfo["version"],
+			Fields:  map[string]interface{}{"draw_offer": player},
+		}); err != nil {
+			return err
+		}
+		h.BroadcastMessage(ctx, roomID, "draw_offered", map[string]string{"player": player})
+
+	case "accept_draw":
+		if offer == "" || offer == player {
+			return fmt.Errorf("no draw offer to accept")
+		}
+		// Предложение проверено в прочитанной комнате: если с тех пор был ход,
+		// снявший его, finish получит ErrConflict
+		return h.finish(ctx, roomID, roomInfo, "tie", "", ReasonAgreement)
+
+	case "decline_draw":
+		if offer == "" || offer == player {
+			return fmt.Errorf("no draw offer to decline")
+		}
+		if _, err := h.Repo.UpdateRoom(roomID, repository.RoomUpdate{
+			Version: roomInfo["version"],
+			Fields:  map[string]interface{}{"draw_offer": ""},
+		}); err != nil {
+			return err
+		}
+		h.BroadcastMessage(ctx, roomID, "draw_declined", map[string]string{"player": offer})
+	}
+	return nil
+}
+
+// processBotDraw отвечает на предложение ничьей боту. Ошибка уходит только предложившему.
+func (h *WebSocketHandler) processBotDraw(ctx context.Context, roomID string, roomInfo map[string]string, player string, conn *websocket.Conn) {
+	if err := h.answerBotDraw(ctx, roomID, roomInfo, player); err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Finish error: %s", err.Error()))
+		h.SendMessage(ctx, conn, "error", map[string]string{
+			"message": err.Error(),
+		})
+	}
+}
+
+// answerBotDraw решает за бота: он соглашается на ничью, только если
+// при лучшей игре обеих сторон не выигрывает сам
+func (h *WebSocketHandler) answerBotDraw(ctx context.Context, roomID string, roomInfo map[string]string, player string) error {
+	g, err := loadGame(roomInfo)
+	if err != nil {
+		return fmt.Errorf("failed to restore game: %w", err)
+	}
+	solver := &bot.AlphaBeta{Budget: h.Bots.Config().ThinkTime}
+	evals, err := solver.Solve(ctx, g)
+	if err != nil {
+		return err
+	}
+
+	// Оценка дана для того, чья очередь хода
+	best := evals[0].Value
+	botWins := best == bot.ValueUnknown ||
+		(roomInfo["turn"] == bot.Name && best == bot.ValueWin) ||
+		(roomInfo["turn"] != bot.Name && best == bot.ValueLoss)
+	if botWins {
+		h.BroadcastMessage(ctx, roomID, "draw_declined", map[string]string{"player": player})
+		return nil
+	}
+	return h.finish(ctx, roomID, roomInfo, "tie", "", ReasonAgreement)
+}
+
+// finish заканчивает партию без хода на доске, останавливает часы и рассылает итог.
+// roomInfo — комната, по которой решено закончить партию: если её изменили
+// после чтения, партия не заканчивается и возвращается ErrConflict.
+func (h *WebSocketHandler) finish(ctx context.Context, roomID string, roomInfo map[string]string, status, winner, reason string) error {
+	if !gameActive(roomInfo) {
+		return game.ErrGameOver
+	}
+	c, err := readClock(roomInfo)
+	if err != nil {
+		return err
+	}
+	c.Stop(time.Now())
+
+	updates := clockFields(c)
+	updates["status"] = status
+	updates["winner"] = winner
+	updates["reason"] = reason
+	updates["draw_offer"] = ""
+	updates["undo_request"] = ""
+	updates["move_deadline"] = 0
+	for field, value := range scoreSeries(roomInfo, status, winner) {
+		updates[field] = value
+	}
+	// Партию могли продолжить ходом или закончить другим способом, пока мы её читали
+	if _, err := h.Repo.UpdateRoom(roomID, withDeadline(repository.RoomUpdate{
+		Version: roomInfo["version"],
+		State:   repository.StateFinished,
+		Fields:  updates,
+	}, roomInfo)); err != nil {
+		return err
+	}
+
+	updatedRoomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch updated room info: %w", err)
+	}
+	h.BroadcastMessage(ctx, roomID, "update", withClock(updatedRoomInfo))
+	h.gameOver(ctx, roomID, updatedRoomInfo)
+	return nil
+}
+
+// gameOver записывает законченную партию в архив, объявляет итог серии,
+// если партия её решила, освобождает движок бота и запускает разбор партии.
+// roomInfo — комната сразу после записи итога партии.
+func (h *WebSocketHandler) gameOver(ctx context.Context, roomID string, roomInfo map[string]string) {
+	if err := h.archiveGame(ctx, roomID); err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Failed to archive game in room %s: %s", roomID, err.Error()))
+	}
+	if seriesDecided(roomInfo) {
+		h.BroadcastMessage(ctx, roomID, "series_over", map[string]interface{}{
+			"status": roomInfo["series_status"],
+			"winner": roomInfo["series_winner"],
+			"score":  readSeries(roomInfo),
+		})
+	}
+	h.Bots.Release(roomID)
+	go h.sendReport(roomID)
+}
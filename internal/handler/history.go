@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"tic_tac_toe/internal/game"
+	"tic_tac_toe/internal/repository"
+	"time"
+)
+
+// withFields дополняет данные комнаты полями, которые хранятся не в её хеше
+func withFields(roomInfo map[string]string, extra map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(roomInfo)+len(extra))
+	for k, v := range roomInfo {
+		data[k] = v
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	return data
+}
+
+// roomState возвращает данные комнаты вместе с историей ходов и показаниями часов
+func roomState(repo repository.RoomStore, roomID string, roomInfo map[string]string) (map[string]interface{}, error) {
+	moves, err := repo.GetMoves(roomID)
+	if err != nil {
+		return nil, err
+	}
+	return withFields(roomInfo, map[string]interface{}{
+		"moves": moves,
+		"clock": clockView(roomInfo, time.Now()),
+	}), nil
+}
+
+// historyMoves переводит записи истории в ходы партии
+func historyMoves(history []repository.Move) ([]game.Move, error) {
+	moves := make([]game.Move, len(history))
+	for i, m := range history {
+		mark, err := game.ParseMark(m.Mark)
+		if err != nil {
+			return nil, err
+		}
+		moves[i] = game.Move{Position: m.Position, Mark: mark}
+	}
+	return moves, nil
+}
+
+// replay переигрывает партию с начала по записям истории
+func replay(cfg game.Config, history []repository.Move) (*game.Game, error) {
+	moves, err := historyMoves(history)
+	if err != nil {
+		return nil, err
+	}
+	g, err := game.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range moves {
+		if _, err := g.Apply(m); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
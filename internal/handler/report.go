@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/repository"
+)
+
+// moveReview — разбор хода в отчёте о партии
+type moveReview struct {
+	Seq          int    `json:"seq"`
+	Player       string `json:"player"`
+	Position     int    `json:"position"`
+	Mark         string `json:"mark"`
+	Before       string `json:"before"` // Лучший исход для игрока до хода
+	After        string `json:"after"`  // Исход для игрока после хода
+	BestPosition int    `json:"best_position"`
+	Mistake      bool   `json:"mistake"`
+}
+
+// gameFinished сообщает, что партия в комнате закончена
+func gameFinished(roomInfo map[string]string) bool {
+	return roomInfo["status"] == "finished" || roomInfo["status"] == "tie"
+}
+
+// gameReport возвращает разбор законченной партии: сохранённый или построенный заново
+func gameReport(ctx context.Context, repo repository.RoomStore, cfg bot.Config, roomID string, roomInfo map[string]string) (json.RawMessage, error) {
+	if !gameFinished(roomInfo) {
+		return nil, fmt.Errorf("game is not finished")
+	}
+
+	gameID := roomInfo["game_id"]
+	report, err := repo.GetReport(roomID, gameID)
+	if err != nil || report != nil {
+		return report, err
+	}
+
+	gameCfg, err := gameConfig(roomInfo)
+	if err != nil {
+		return nil, err
+	}
+	history, err := repo.GetMoves(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	moves, err := historyMoves(history)
+	if err != nil {
+		return nil, err
+	}
+
+	// Позиций в партии много, поэтому на каждую уходит лишь часть времени хода бота
+	solver := &bot.AlphaBeta{Budget: cfg.ThinkTime / 10}
+	reviews, err := bot.ReviewGame(ctx, solver, gameCfg, moves)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]moveReview, len(reviews))
+	for i, r := range reviews {
+		result[i] = moveReview{
+			Seq:          history[i].Seq,
+			Player:       history[i].Player,
+			Position:     r.Move.Position,
+			Mark:         r.Move.Mark.String(),
+			Before:       string(r.Before),
+			After:        string(r.After),
+			BestPosition: r.Best.Position,
+			Mistake:      r.Mistake,
+		}
+	}
+
+	report, err = json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	// Пока партию разбирали, в комнате могла начаться новая: такой разбор не сохраняется
+	if err := repo.SaveReport(roomID, gameID, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// sendReport разбирает законченную партию и рассылает разбор игрокам
+func (h *WebSocketHandler) sendReport(roomID string) {
+	ctx := context.Background()
+
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Failed to fetch room %s for analysis: %s", roomID, err.Error()))
+		return
+	}
+
+	report, err := gameReport(ctx, h.Repo, h.Bots.Config(), roomID, roomInfo)
+	if err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Failed to analyse game in room %s: %s", roomID, err.Error()))
+		return
+	}
+
+	h.BroadcastMessage(ctx, roomID, "game_analysis", report)
+}
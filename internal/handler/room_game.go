@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"strconv"
+	"tic_tac_toe/internal/game"
+)
+
+// gameConfig восстанавливает параметры поля из данных комнаты.
+// Комнаты, созданные до появления настроек, считаются классическими 3x3.
+func gameConfig(roomInfo map[string]string) (game.Config, error) {
+	cfg := game.Classic()
+
+	mode, err := game.ParseMode(roomInfo["mode"])
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Mode = mode
+
+	rules, err := game.ParseRules(roomInfo["rules"])
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Rules = rules
+
+	// Знак того, кто ходит первым в текущей партии; по умолчанию X
+	if mark := roomInfo["first_mark"]; mark != "" {
+		if cfg.FirstMark, err = game.ParseMark(mark); err != nil {
+			return cfg, err
+		}
+	}
+
+	for field, dst := range map[string]*int{
+		"width":      &cfg.Width,
+		"height":     &cfg.Height,
+		"depth":      &cfg.Depth,
+		"win_length": &cfg.WinLength,
+	} {
+		value, ok := roomInfo[field]
+		if !ok || value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return cfg, game.ErrInvalidConfig
+		}
+		*dst = n
+	}
+	return cfg, cfg.Validate()
+}
+
+// gameState восстанавливает состояние партии из данных комнаты
+func gameState(roomInfo map[string]string) (game.State, error) {
+	st := game.State{Board: roomInfo["board"], Turn: playerOf(roomInfo, roomInfo["turn"]), Next: -1}
+	if next := roomInfo["next_board"]; next != "" {
+		n, err := strconv.Atoi(next)
+		if err != nil {
+			return st, game.ErrInvalidBoard
+		}
+		st.Next = n
+	}
+	return st, nil
+}
+
+// loadGame восстанавливает партию комнаты
+func loadGame(roomInfo map[string]string) (*game.Game, error) {
+	cfg, err := gameConfig(roomInfo)
+	if err != nil {
+		return nil, err
+	}
+	st, err := gameState(roomInfo)
+	if err != nil {
+		return nil, err
+	}
+	return game.Parse(cfg, st)
+}
+
+// seats возвращает пользователей в порядке хода. Первым ходит пользователь
+// из поля first (его выбирает chooseSides), по умолчанию — создатель комнаты.
+func seats(roomInfo map[string]string) (first, second string) {
+	if roomInfo["first"] != "" && roomInfo["first"] == roomInfo["user2"] {
+		return roomInfo["user2"], roomInfo["user1"]
+	}
+	return roomInfo["user1"], roomInfo["user2"]
+}
+
+// playerOf возвращает роль пользователя в партии
+func playerOf(roomInfo map[string]string, user string) game.Player {
+	if _, second := seats(roomInfo); user != "" && user == second {
+		return game.Second
+	}
+	return game.First
+}
+
+// userOf возвращает имя пользователя, играющего за игрока
+func userOf(roomInfo map[string]string, p game.Player) string {
+	first, second := seats(roomInfo)
+	if p == game.Second {
+		return second
+	}
+	return first
+}
+
+// parsePosition извлекает клетку хода из сообщения: номер клетки в поле
+// position, пару координат row/col, в ультимативном режиме — пару sub_board/cell,
+// в режиме с падением фишек — только столбец column, а в кубе — тройку x/y/z
+func parsePosition(g *game.Game, data map[string]string) (int, error) {
+	cfg := g.Config()
+
+	if data["x"] != "" || data["y"] != "" || data["z"] != "" {
+		var xyz [3]int
+		for i, key := range []string{"x", "y", "z"} {
+			n, err := strconv.Atoi(data[key])
+			if err != nil {
+				return 0, game.ErrInvalidPosition
+			}
+			xyz[i] = n
+		}
+		if cfg.Mode != game.Cube {
+			return 0, game.ErrInvalidPosition
+		}
+		// x — столбец, y — строка, z — слой
+		return cfg.Index3(xyz[2], xyz[1], xyz[0])
+	}
+
+	if data["column"] != "" {
+		col, err := strconv.Atoi(data["column"])
+		if err != nil {
+			return 0, game.ErrInvalidPosition
+		}
+		return g.Drop(col)
+	}
+
+	if data["sub_board"] != "" || data["cell"] != "" {
+		sub, err := strconv.Atoi(data["sub_board"])
+		if err != nil {
+			return 0, game.ErrInvalidPosition
+		}
+		cell, err := strconv.Atoi(data["cell"])
+		if err != nil {
+			return 0, game.ErrInvalidPosition
+		}
+		if cfg.Mode != game.Ultimate {
+			return 0, game.ErrInvalidPosition
+		}
+		return game.UltimateIndex(sub, cell)
+	}
+
+	if data["row"] != "" || data["col"] != "" {
+		row, err := strconv.Atoi(data["row"])
+		if err != nil {
+			return 0, game.ErrInvalidPosition
+		}
+		col, err := strconv.Atoi(data["col"])
+		if err != nil {
+			return 0, game.ErrInvalidPosition
+		}
+		return cfg.Index(row, col)
+	}
+
+	pos, err := strconv.Atoi(data["position"])
+	if err != nil {
+		return 0, game.ErrInvalidPosition
+	}
+	return pos, nil
+}
@@ -2,10 +2,17 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"tic_tac_toe/internal/archive"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/clock"
+	"tic_tac_toe/internal/game"
 	"tic_tac_toe/internal/repository"
 	"tic_tac_toe/pkg/logger"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
@@ -13,8 +20,10 @@ import (
 )
 
 type RoomHandler struct {
-	Repo   *repository.RoomRepository
-	Logger logger.Logger
+	Repo    repository.RoomStore
+	Logger  logger.Logger
+	Bots    *bot.Pool
+	Archive *archive.Archive // Архив законченных партий, nil — отключён
 }
 
 // CustomValidator связывает Echo с библиотекой валидации
@@ -39,10 +48,42 @@ func (h *RoomHandler) respond(c echo.Context, status int, data interface{}) erro
 	return c.JSON(status, data)
 }
 
+// errorStatus возвращает HTTP-статус для ошибки репозитория: запрещённый
+// переход и гонка за комнату — конфликт с её текущим состоянием
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrRoomNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrRoomFull),
+		errors.Is(err, repository.ErrNicknameTaken),
+		errors.Is(err, repository.ErrInvalidTransition),
+		errors.Is(err, repository.ErrConflict):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
 // Создать комнату
 func (h *RoomHandler) CreateRoom(c echo.Context) error {
 	type request struct {
-		Admin string `json:"admin" validate:"required"`
+		Admin          string `json:"admin" validate:"required"`
+		Mode           string `json:"mode" validate:"omitempty,oneof=standard ultimate gravity cube"`
+		Width          int    `json:"width" validate:"omitempty,min=3,max=19"`
+		Height         int    `json:"height" validate:"omitempty,min=3,max=19"`
+		Depth          int    `json:"depth" validate:"omitempty,min=3,max=5"`
+		WinLength      int    `json:"win_length" validate:"omitempty,min=3,max=19"`
+		Rules          string `json:"rules" validate:"omitempty,oneof=standard misere wild notakto order_chaos"`
+		Bot            bool   `json:"bot"` // Второй игрок — бот, игра начинается сразу
+		BotLevel       string `json:"bot_level" validate:"omitempty,oneof=easy medium hard perfect"`
+		AutoUndo       bool   `json:"auto_undo"`                                       // Дружеская партия: просьбы вернуть ход принимаются без соперника
+		SeriesLength   int    `json:"series_length" validate:"omitempty,min=1,max=99"` // Серия до большинства побед из N партий
+		ClockKind      string `json:"clock_kind" validate:"omitempty,oneof=fischer bronstein"`
+		ClockInitial   int    `json:"clock_initial" validate:"omitempty,min=1,max=36000"` // Секунд на партию, 0 — без часов
+		ClockIncrement int    `json:"clock_increment" validate:"omitempty,min=0,max=600"` // Прибавка (Фишер) или задержка (Бронштейн) на ход в секундах
+		MoveLimit      int    `json:"move_limit" validate:"omitempty,min=1,max=3600"`     // Секунд на ход, 0 — без ограничения
+		MoveTimeout    string `json:"move_timeout" validate:"omitempty,oneof=forfeit random bot"`
+		FirstMove      string `json:"first_move" validate:"omitempty,oneof=alternate creator joiner random loser"`
+		Symbol         string `json:"symbol" validate:"omitempty,oneof=X O x o"` // Знак создателя во всех партиях, по умолчанию первый ходит X
 	}
 
 	var req request
@@ -59,18 +100,118 @@ func (h *RoomHandler) CreateRoom(c echo.Context) error {
 			"detail": err.Error(),
 		})
 	}
+
+	if req.Admin == bot.Name {
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": "nickname is reserved"})
+	}
+
+	// Режим, размер поля, длина линии и правила; незаданные параметры дают классическое поле 3x3
+	cfg := game.Config{
+		Mode:      game.Mode(req.Mode),
+		Width:     req.Width,
+		Height:    req.Height,
+		Depth:     req.Depth,
+		WinLength: req.WinLength,
+		Rules:     game.Rules(req.Rules),
+	}.WithDefaults()
+	if err := cfg.Validate(); err != nil {
+		h.Logger.Error(c.Request().Context(), "Invalid board configuration: "+err.Error())
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Настройки комнаты, не относящиеся к полю
+	settings := map[string]interface{}{
+		"auto_undo":     strconv.FormatBool(req.AutoUndo),
+		"series_length": max(req.SeriesLength, 1),
+		"series_status": "ongoing",
+		"series_wins1":  0,
+		"series_wins2":  0,
+		"series_draws":  0,
+	}
+	// Стороны выбираются при старте каждой партии, когда соперник уже известен
+	settings["first_move"] = FirstAlternate
+	if req.FirstMove != "" {
+		settings["first_move"] = req.FirstMove
+	}
+	if req.Symbol != "" {
+		mark, _ := game.ParseMark(req.Symbol)
+		settings["creator_mark"] = mark.String()
+	}
+
+	// Не успевший походить игрок по умолчанию проигрывает
+	if req.MoveLimit > 0 {
+		settings["move_limit"] = (time.Duration(req.MoveLimit) * time.Second).Milliseconds()
+		settings["move_timeout"] = TimeoutForfeit
+		if req.MoveTimeout != "" {
+			settings["move_timeout"] = req.MoveTimeout
+		}
+	}
+
+	// Часы стоят до первого хода
+	kind, _ := clock.ParseKind(req.ClockKind)
+	control := clock.Control{
+		Kind:      kind,
+		Initial:   time.Duration(req.ClockInitial) * time.Second,
+		Increment: time.Duration(req.ClockIncrement) * time.Second,
+	}
+	for field, value := range clockFields(clock.New(control)) {
+		settings[field] = value
+	}
+
 	// Уникальный ID комнаты
-	roomID := "room-" + uuid.New().String()
-	err := h.Repo.CreateRoom(roomID, req.Admin)
-	if err != nil {
+	roomID := repository.RoomIDPrefix + uuid.New().String()
+	if err := h.Repo.CreateRoom(roomID, req.Admin, cfg, settings); err != nil {
 		h.Logger.Error(c.Request().Context(), "Failed to create room: "+err.Error())
 		return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to create room"})
 	}
 
+	// Бот сразу занимает второе место, ждать соперника не нужно
+	var user2, botLevel interface{}
+	if req.Bot {
+		level, _ := bot.ParseLevel(req.BotLevel)
+		if err := h.Repo.JoinRoom(roomID, bot.Name); err != nil {
+			h.Logger.Error(c.Request().Context(), "Failed to add bot: "+err.Error())
+			return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to create room"})
+		}
+		roomInfo, err := h.Repo.GetRoomInfo(roomID)
+		if err != nil {
+			h.Logger.Error(c.Request().Context(), "Failed to get room info: "+err.Error())
+			return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to create room"})
+		}
+		fields, err := newGameFields(roomInfo)
+		if err != nil {
+			h.Logger.Error(c.Request().Context(), "Failed to prepare game: "+err.Error())
+			return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to create room"})
+		}
+		// Уровень хранится рядом с user2, чтобы клиенты могли подписать соперника
+		fields["bot_level"] = string(level)
+		if err := h.Repo.StartGame(roomID, fields, roomDeadline(afterUpdate(roomInfo, fields))); err != nil {
+			h.Logger.Error(c.Request().Context(), "Failed to start game with bot: "+err.Error())
+			return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to create room"})
+		}
+		user2, botLevel = bot.Name, level
+	}
+
 	return h.respond(c, http.StatusOK, map[string]interface{}{
-		"roomID": roomID,
-		"user1":  req.Admin,
-		"user2":  nil,
+		"roomID":          roomID,
+		"user1":           req.Admin,
+		"user2":           user2,
+		"bot_level":       botLevel,
+		"mode":            cfg.Mode,
+		"width":           cfg.Width,
+		"height":          cfg.Height,
+		"depth":           cfg.Depth,
+		"win_length":      cfg.WinLength,
+		"rules":           cfg.Rules,
+		"auto_undo":       req.AutoUndo,
+		"series_length":   max(req.SeriesLength, 1),
+		"clock_kind":      kind,
+		"clock_initial":   req.ClockInitial,
+		"clock_increment": req.ClockIncrement,
+		"move_limit":      req.MoveLimit,
+		"move_timeout":    settings["move_timeout"],
+		"first_move":      settings["first_move"],
+		"symbol":          settings["creator_mark"],
 	})
 }
 
@@ -93,10 +234,14 @@ func (h *RoomHandler) JoinRoom(c echo.Context) error {
 		return h.respond(c, http.StatusBadRequest, map[string]string{"error": "Validation failed"})
 	}
 
+	if req.User == bot.Name {
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": "nickname is reserved"})
+	}
+
 	err := h.Repo.JoinRoom(req.RoomID, req.User)
 	if err != nil {
 		h.Logger.Error(c.Request().Context(), "Failed to join room: "+err.Error())
-		return h.respond(c, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return h.respond(c, errorStatus(err), map[string]string{"error": err.Error()})
 	}
 
 	roomInfo, _ := h.Repo.GetRoomInfo(req.RoomID) // Игнорируем ошибку, так как комната должна существовать
@@ -122,9 +267,52 @@ func (h *RoomHandler) GetRoomInfo(c echo.Context) error {
 		return h.respond(c, http.StatusNotFound, map[string]string{"error": "Room not found"})
 	}
 
+	state, err := roomState(h.Repo, roomID, roomInfo)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to get move history: "+err.Error())
+		return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to get move history"})
+	}
+
 	// Логируем успешное извлечение информации
 	h.Logger.Info(c.Request().Context(), fmt.Sprintf("Room info fetched successfully for roomID: %s", roomID))
-	return h.respond(c, http.StatusOK, roomInfo)
+	return h.respond(c, http.StatusOK, state)
+}
+
+// Получить список комнат; параметр state оставляет только комнаты в этом
+// состоянии, например, state=waiting — комнаты, которые ждут соперника
+func (h *RoomHandler) ListRooms(c echo.Context) error {
+	state := repository.RoomState(c.QueryParam("state"))
+	switch state {
+	case "", repository.StateWaiting, repository.StateReady, repository.StateStarted,
+		repository.StateFinished, repository.StateArchived:
+	default:
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": "Invalid state"})
+	}
+
+	roomIDs, err := h.Repo.ListRooms(state)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to list rooms: "+err.Error())
+		return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to list rooms"})
+	}
+
+	rooms := make([]map[string]interface{}, 0, len(roomIDs))
+	for _, roomID := range roomIDs {
+		roomInfo, err := h.Repo.GetRoomInfo(roomID)
+		if err != nil {
+			continue // Комнату удалили, пока составлялся список
+		}
+		rooms = append(rooms, map[string]interface{}{
+			"roomID": roomID,
+			"user1":  roomInfo["user1"],
+			"user2":  roomInfo["user2"],
+			"state":  repository.StateOf(roomInfo),
+			"mode":   roomInfo["mode"],
+			"rules":  roomInfo["rules"],
+			"width":  roomInfo["width"],
+			"height": roomInfo["height"],
+		})
+	}
+	return h.respond(c, http.StatusOK, map[string]interface{}{"rooms": rooms})
 }
 
 // Удалить комнату
@@ -189,23 +377,112 @@ func (h *RoomHandler) RemoveUser(c echo.Context) error {
 	err = h.Repo.RemoveUser(req.RoomID)
 	if err != nil {
 		h.Logger.Error(c.Request().Context(), "Failed to remove user: "+err.Error())
-		return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to remove user"})
+		return h.respond(c, errorStatus(err), map[string]string{"error": err.Error()})
 	}
 
 	return h.respond(c, http.StatusOK, map[string]string{"message": "User removed successfully"})
 }
 
+// Перенести законченную комнату в архив: после этого в ней нельзя начать
+// новую партию, а комната живёт ещё ROOM_TTL_ARCHIVED
+func (h *RoomHandler) ArchiveRoom(c echo.Context) error {
+	type request struct {
+		RoomID string `json:"roomID" validate:"required"`
+		Admin  string `json:"admin" validate:"required"`
+	}
+
+	var req request
+	if err := c.Bind(&req); err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to parse request: "+err.Error())
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": "Invalid request"})
+	}
+
+	// Валидация данных
+	if err := c.Validate(&req); err != nil {
+		h.Logger.Error(c.Request().Context(), "Validation error: "+err.Error())
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": "Validation failed"})
+	}
+
+	roomInfo, err := h.Repo.GetRoomInfo(req.RoomID)
+	if err != nil || roomInfo["admin"] != req.Admin {
+		h.Logger.Error(c.Request().Context(), "Unauthorized archive attempt")
+		return h.respond(c, http.StatusForbidden, map[string]string{"error": "Only the admin can archive the room"})
+	}
+
+	// Архивировать можно только законченную комнату, это проверяет сам переход
+	err = h.Repo.ArchiveRoom(req.RoomID)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to archive room: "+err.Error())
+		return h.respond(c, errorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	return h.respond(c, http.StatusOK, map[string]string{"message": "Room archived successfully"})
+}
+
 // Начать игру
 func (h *RoomHandler) StartGame(c echo.Context) error {
 	roomID := c.Param("room_id")
 
-	// Пытаемся начать игру
-	err := h.Repo.StartGame(roomID)
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to get room info: "+err.Error())
+		return h.respond(c, errorStatus(err), map[string]string{"error": err.Error()})
+	}
+
+	// Пытаемся начать игру; если в комнате уже играли с прежним соперником, поле очищается.
+	// Стороны и срок первого хода записываются вместе со стартом.
+	fields, err := newGameFields(roomInfo)
 	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to prepare game: "+err.Error())
+		return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to start game"})
+	}
+	if err := h.Repo.StartGame(roomID, fields, roomDeadline(afterUpdate(roomInfo, fields))); err != nil {
 		h.Logger.Error(c.Request().Context(), "Failed to start game: "+err.Error())
-		return h.respond(c, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return h.respond(c, errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	if err := h.Repo.DeleteReport(roomID); err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to delete old report: "+err.Error())
+		return h.respond(c, http.StatusInternalServerError, map[string]string{"error": "Failed to start game"})
 	}
 
 	// Игра началась, теперь можно отправить сообщение всем игрокам
 	return h.respond(c, http.StatusOK, map[string]string{"message": "Game started"})
 }
+
+// Оценить ходы в текущей позиции
+func (h *RoomHandler) GetAnalysis(c echo.Context) error {
+	roomID := c.Param("room_id")
+
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to get room info: "+err.Error())
+		return h.respond(c, http.StatusNotFound, map[string]string{"error": "Room not found"})
+	}
+
+	analysis, err := analyseRoom(c.Request().Context(), h.Bots.Config(), roomInfo)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to analyse room: "+err.Error())
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return h.respond(c, http.StatusOK, analysis)
+}
+
+// Получить разбор законченной партии
+func (h *RoomHandler) GetReport(c echo.Context) error {
+	roomID := c.Param("room_id")
+
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to get room info: "+err.Error())
+		return h.respond(c, http.StatusNotFound, map[string]string{"error": "Room not found"})
+	}
+
+	report, err := gameReport(c.Request().Context(), h.Repo, h.Bots.Config(), roomID, roomInfo)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), "Failed to build game report: "+err.Error())
+		return h.respond(c, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return h.respond(c, http.StatusOK, report)
+}
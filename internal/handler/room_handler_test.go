@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/repository"
+	"tic_tac_toe/pkg/logger"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// newTestServer собирает обработчики вокруг хранилища в памяти, без Redis и архива
+func newTestServer(t *testing.T) (*echo.Echo, *WebSocketHandler, repository.RoomStore) {
+	t.Helper()
+	repo := repository.NewMemoryRepository(repository.TTL{
+		Waiting:  time.Minute,
+		Ready:    time.Minute,
+		Started:  time.Hour,
+		Finished: time.Hour,
+		Archived: time.Hour,
+	})
+	log := logger.New("test")
+	bots := bot.NewPool(bot.Config{ThinkTime: 100 * time.Millisecond, Workers: 1}, nil)
+
+	rooms := &RoomHandler{Repo: repo, Logger: log, Bots: bots}
+	ws := &WebSocketHandler{
+		Repo:    repo,
+		Logger:  log,
+		Clients: make(map[string][]*websocket.Conn),
+		Bots:    bots,
+	}
+
+	e := echo.New()
+	e.Validator = &CustomValidator{Validator: validator.New()}
+	e.POST("/room/create", rooms.CreateRoom)
+	e.POST("/room/join", rooms.JoinRoom)
+	e.GET("/room/start/:room_id", rooms.StartGame)
+	e.POST("/room/archive", rooms.ArchiveRoom)
+	e.GET("/ws/:room_id", ws.HandleConnection)
+	return e, ws, repo
+}
+
+// call выполняет запрос к обработчикам и разбирает JSON-ответ
+func call(t *testing.T, e *echo.Echo, method, path string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: bad response %q", method, path, rec.Body.String())
+	}
+	return rec.Code, resp
+}
+
+// startGame создаёт комнату alice против bob и начинает партию
+func startGame(t *testing.T, e *echo.Echo, settings map[string]interface{}) string {
+	t.Helper()
+	body := map[string]interface{}{"admin": "alice"}
+	for k, v := range settings {
+		body[k] = v
+	}
+	_, resp := call(t, e, http.MethodPost, "/room/create", body)
+	roomID, _ := resp["roomID"].(string)
+	call(t, e, http.MethodPost, "/room/join", map[string]string{"roomID": roomID, "user": "bob"})
+	if code, resp := call(t, e, http.MethodGet, "/room/start/"+roomID, nil); code != http.StatusOK {
+		t.Fatalf("start: %d %v", code, resp)
+	}
+	return roomID
+}
+
+// connect подключается к комнате по WebSocket и возвращает присланное начальное состояние
+func connect(t *testing.T, server *httptest.Server, roomID string) (*websocket.Conn, map[string]interface{}) {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/" + roomID
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var msg struct {
+		Type string                 `json:"type"`
+		Data map[string]interface{} `json:"data"`
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "initial_state" {
+		t.Fatalf("initial state: %v, type %q", err, msg.Type)
+	}
+	return conn, msg.Data
+}
+
+func TestRoomFlow(t *testing.T) {
+	e, ws, repo := newTestServer(t)
+	ctx := context.Background()
+
+	code, resp := call(t, e, http.MethodPost, "/room/create", map[string]interface{}{"admin": "alice"})
+	if code != http.StatusOK {
+		t.Fatalf("create: %d %v", code, resp)
+	}
+	roomID, _ := resp["roomID"].(string)
+
+	if code, resp := call(t, e, http.MethodGet, "/room/start/"+roomID, nil); code != http.StatusConflict {
+		t.Errorf("start without opponent: %d %v", code, resp)
+	}
+	if code, resp := call(t, e, http.MethodPost, "/room/join", map[string]string{"roomID": roomID, "user": "bob"}); code != http.StatusOK {
+		t.Fatalf("join: %d %v", code, resp)
+	}
+	if code, resp := call(t, e, http.MethodPost, "/room/join", map[string]string{"roomID": roomID, "user": "carol"}); code != http.StatusConflict {
+		t.Errorf("join full room: %d %v", code, resp)
+	}
+	if code, resp := call(t, e, http.MethodGet, "/room/start/"+roomID, nil); code != http.StatusOK {
+		t.Fatalf("start: %d %v", code, resp)
+	}
+	// Второй старт уже идущей партии — конфликт, а не новая партия
+	if code, resp := call(t, e, http.MethodGet, "/room/start/"+roomID, nil); code != http.StatusConflict {
+		t.Errorf("second start: %d %v", code, resp)
+	}
+
+	// Стороны выбраны при старте: в первой партии первым ходит создатель
+	roomInfo, err := repo.GetRoomInfo(roomID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roomInfo["first"] != "alice" || roomInfo["turn"] != "alice" || roomInfo["first_mark"] != "X" {
+		t.Fatalf("sides: first %s, turn %s, mark %s", roomInfo["first"], roomInfo["turn"], roomInfo["first_mark"])
+	}
+
+	if err := ws.processMove(ctx, roomID, "bob", map[string]string{"position": "4"}); err == nil {
+		t.Error("move out of turn accepted")
+	}
+	if code, resp := call(t, e, http.MethodPost, "/room/archive", map[string]string{"roomID": roomID, "admin": "alice"}); code != http.StatusConflict {
+		t.Errorf("archive during game: %d %v", code, resp)
+	}
+
+	moves := []struct {
+		player   string
+		position string
+	}{
+		{"alice", "0"}, {"bob", "3"}, {"alice", "1"}, {"bob", "4"}, {"alice", "2"},
+	}
+	for _, m := range moves {
+		if err := ws.processMove(ctx, roomID, m.player, map[string]string{"position": m.position}); err != nil {
+			t.Fatalf("%s to %s: %v", m.player, m.position, err)
+		}
+	}
+
+	roomInfo, _ = repo.GetRoomInfo(roomID)
+	if roomInfo["status"] != "finished" || roomInfo["winner"] != "alice" || roomInfo["reason"] != ReasonLine {
+		t.Errorf("result: status %s, winner %s, reason %s", roomInfo["status"], roomInfo["winner"], roomInfo["reason"])
+	}
+	if repository.StateOf(roomInfo) != repository.StateFinished || roomInfo["series_wins1"] != "1" {
+		t.Errorf("state %s, series_wins1 %s", repository.StateOf(roomInfo), roomInfo["series_wins1"])
+	}
+	if history, _ := repo.GetMoves(roomID); len(history) != len(moves) {
+		t.Errorf("history has %d moves", len(history))
+	}
+	if err := ws.processMove(ctx, roomID, "bob", map[string]string{"position": "8"}); err == nil {
+		t.Error("move after the end accepted")
+	}
+
+	if code, resp := call(t, e, http.MethodPost, "/room/archive", map[string]string{"roomID": roomID, "admin": "bob"}); code != http.StatusForbidden {
+		t.Errorf("archive by non-admin: %d %v", code, resp)
+	}
+	if code, resp := call(t, e, http.MethodPost, "/room/archive", map[string]string{"roomID": roomID, "admin": "alice"}); code != http.StatusOK {
+		t.Fatalf("archive: %d %v", code, resp)
+	}
+	if code, resp := call(t, e, http.MethodGet, "/room/start/"+roomID, nil); code != http.StatusConflict {
+		t.Errorf("start archived room: %d %v", code, resp)
+	}
+}
+
+func TestStaleMoveRejected(t *testing.T) {
+	e, ws, repo := newTestServer(t)
+	ctx := context.Background()
+
+	roomID := startGame(t, e, map[string]interface{}{"move_limit": 30})
+	// Срок первого хода назначен вместе со стартом
+	if due, _ := repo.DueDeadlines(time.Now().Add(time.Hour)); len(due) != 1 || due[0] != roomID {
+		t.Errorf("deadlines after start: %v", due)
+	}
+
+	// Срок хода прошёл, а сервер ещё не успел сходить за игрока
+	if _, err := repo.UpdateRoom(roomID, repository.RoomUpdate{
+		Version: repository.AnyVersion,
+		Fields:  map[string]interface{}{"move_deadline": time.Now().Add(-time.Second).UnixMilli()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.processMove(ctx, roomID, "alice", map[string]string{"position": "0"}); err == nil {
+		t.Error("move after the deadline accepted")
+	}
+	if history, _ := repo.GetMoves(roomID); len(history) != 0 {
+		t.Errorf("history has %d moves", len(history))
+	}
+}
+
+func TestReconnectMidGame(t *testing.T) {
+	e, ws, _ := newTestServer(t)
+	server := httptest.NewServer(e)
+	defer server.Close()
+	roomID := startGame(t, e, nil)
+
+	connect(t, server, roomID)
+	if err := ws.processMove(context.Background(), roomID, "alice", map[string]string{"position": "4"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// После первого хода статус партии ongoing, но подключиться заново можно
+	_, state := connect(t, server, roomID)
+	if moves, _ := state["moves"].([]interface{}); len(moves) != 1 {
+		t.Errorf("initial state has %d moves", len(moves))
+	}
+	if state["status"] != "ongoing" || state["turn"] != "bob" {
+		t.Errorf("status %v, turn %v", state["status"], state["turn"])
+	}
+}
+
+func TestStaleDrawAcceptance(t *testing.T) {
+	e, ws, repo := newTestServer(t)
+	ctx := context.Background()
+	roomID := startGame(t, e, nil)
+
+	if err := ws.processFinish(ctx, roomID, "alice", "offer_draw", nil); err != nil {
+		t.Fatal(err)
+	}
+	// bob прочитал комнату с предложением, но alice успела походить и сняла его
+	offered, _ := repo.GetRoomInfo(roomID)
+	if err := ws.processMove(ctx, roomID, "alice", map[string]string{"position": "4"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.finish(ctx, roomID, offered, "tie", "", ReasonAgreement); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("finish by stale offer: err = %v", err)
+	}
+	if err := ws.processFinish(ctx, roomID, "bob", "accept_draw", nil); err == nil {
+		t.Error("accepted a withdrawn draw offer")
+	}
+
+	roomInfo, _ := repo.GetRoomInfo(roomID)
+	if roomInfo["status"] != "ongoing" {
+		t.Errorf("status %s", roomInfo["status"])
+	}
+}
+
+// flakyStore не может подписаться на удалённые комнаты с первого раза
+type flakyStore struct {
+	repository.RoomStore
+	calls    int
+	watching chan struct{}
+}
+
+func (s *flakyStore) WatchExpired(ctx context.Context, handle func(roomID string, state repository.RoomState)) error {
+	s.calls++
+	if s.calls == 1 {
+		return errors.New("connection refused")
+	}
+	close(s.watching)
+	return s.RoomStore.WatchExpired(ctx, handle)
+}
+
+func TestExpiryResubscribe(t *testing.T) {
+	e, ws, repo := newTestServer(t)
+	store := &flakyStore{RoomStore: repo, watching: make(chan struct{})}
+	ws.Repo = store
+	server := httptest.NewServer(e)
+	defer server.Close()
+	roomID := startGame(t, e, nil)
+	conn, _ := connect(t, server, roomID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ws.WatchExpiries(ctx)
+
+	// Первая подписка не удалась, вторая — через expiryRetryMin
+	select {
+	case <-store.watching:
+	case <-time.After(expiryRetryMin + 5*time.Second):
+		t.Fatal("did not resubscribe")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if ok, err := repo.ExpireRoom(roomID, time.Now().Add(2*time.Hour)); !ok || err != nil {
+		t.Fatalf("ExpireRoom: %v, %v", ok, err)
+	}
+
+	var msg struct {
+		Type string `json:"type"`
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "room_expired" {
+		t.Errorf("message %q, err %v", msg.Type, err)
+	}
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/clock"
+	"tic_tac_toe/internal/game"
+	"tic_tac_toe/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// seriesScore — счёт серии партий в комнате
+type seriesScore struct {
+	Length int            `json:"length"` // Партий в серии, 1 — без серии, счёт просто копится
+	Wins   map[string]int `json:"wins"`   // Победы по именам игроков
+	Draws  int            `json:"draws"`
+}
+
+// seriesField возвращает поле комнаты, в котором считается итог партии
+func seriesField(roomInfo map[string]string, status, winner string) string {
+	switch {
+	case status == "tie":
+		return "series_draws"
+	case winner == roomInfo["user2"]:
+		return "series_wins2"
+	}
+	return "series_wins1"
+}
+
+// readSeries читает счёт серии из данных комнаты
+func readSeries(roomInfo map[string]string) seriesScore {
+	atoi := func(field string) int {
+		n, _ := strconv.Atoi(roomInfo[field])
+		return n
+	}
+	return seriesScore{
+		Length: max(atoi("series_length"), 1),
+		Wins: map[string]int{
+			roomInfo["user1"]: atoi("series_wins1"),
+			roomInfo["user2"]: atoi("series_wins2"),
+		},
+		Draws: atoi("series_draws"),
+	}
+}
+
+// scoreSeries возвращает поля счёта серии с учётом только что законченной партии.
+// Они пишутся вместе с итогом партии, поэтому счёт не расходится с ней.
+// Серия решена, когда кто-то набрал больше половины побед или сыграны все партии.
+func scoreSeries(roomInfo map[string]string, status, winner string) map[string]interface{} {
+	field := seriesField(roomInfo, status, winner)
+	n, _ := strconv.Atoi(roomInfo[field])
+	fields := map[string]interface{}{field: n + 1}
+
+	scored := make(map[string]string, len(roomInfo))
+	for k, v := range roomInfo {
+		scored[k] = v
+	}
+	scored[field] = strconv.Itoa(n + 1)
+	score := readSeries(scored)
+	if score.Length == 1 {
+		return fields
+	}
+	user1, user2 := roomInfo["user1"], roomInfo["user2"]
+	wins1, wins2 := score.Wins[user1], score.Wins[user2]
+	need := score.Length/2 + 1
+	if wins1 < need && wins2 < need && wins1+wins2+score.Draws < score.Length {
+		return fields
+	}
+
+	fields["series_status"], fields["series_winner"] = "tie", ""
+	switch {
+	case wins1 > wins2:
+		fields["series_status"], fields["series_winner"] = "finished", user1
+	case wins2 > wins1:
+		fields["series_status"], fields["series_winner"] = "finished", user2
+	}
+	return fields
+}
+
+// seriesDecided сообщает, что серия в комнате решена
+func seriesDecided(roomInfo map[string]string) bool {
+	return roomInfo["series_status"] == "finished" || roomInfo["series_status"] == "tie"
+}
+
+// unscoreSeries возвращает поля счёта серии без партии, последний ход которой вернули
+func unscoreSeries(roomInfo map[string]string) map[string]interface{} {
+	field := seriesField(roomInfo, roomInfo["status"], roomInfo["winner"])
+	n, _ := strconv.Atoi(roomInfo[field])
+	return map[string]interface{}{
+		field:           max(n-1, 0),
+		"series_status": "ongoing",
+		"series_winner": "",
+	}
+}
+
+// processRematch обрабатывает offer_rematch и accept_rematch. Предложение
+// хранится в поле rematch_offer, бот соглашается сразу.
+func (h *WebSocketHandler) processRematch(ctx context.Context, roomID, player, action string) error {
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch room info: %w", err)
+	}
+	if player == "" || (player != roomInfo["user1"] && player != roomInfo["user2"]) {
+		return fmt.Errorf("you are not a player in this room")
+	}
+	if !gameFinished(roomInfo) {
+		return fmt.Errorf("game is not finished")
+	}
+	offer := roomInfo["rematch_offer"]
+
+	switch action {
+	case "offer_rematch":
+		if offer != "" {
+			return fmt.Errorf("rematch already offered")
+		}
+		if h.getNextPlayer(roomInfo, player) == bot.Name {
+			return h.rematch(ctx, roomID, roomInfo)
+		}
+		if _, err := h.Repo.UpdateRoom(roomID, repository.RoomUpdate{
+			Version: roomInfo["version"],
+			Fields:  map[string]interface{}{"rematch_offer": player},
+		}); err != nil {
+			return err
+		}
+		h.BroadcastMessage(ctx, roomID, "rematch_offered", map[string]string{"player": player})
+
+	case "accept_rematch":
+		if offer == "" || offer == player {
+			return fmt.Errorf("no rematch offer to accept")
+		}
+		return h.rematch(ctx, roomID, roomInfo)
+	}
+	return nil
+}
+
+// newGameFields возвращает поля комнаты для новой партии: пустое поле,
+// стороны, срок первого хода и полные часы, которые стоят до первого хода.
+// Партия начинается одной записью этих полей, поэтому ход не может попасть
+// между стартом и выбором сторон. У каждой партии свой game_id, под которым
+// она попадёт в архив.
+func newGameFields(roomInfo map[string]string) (map[string]interface{}, error) {
+	cfg, err := gameConfig(roomInfo)
+	if err != nil {
+		return nil, err
+	}
+	g, err := game.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c, err := readClock(roomInfo)
+	if err != nil {
+		return nil, err
+	}
+	fields := clockFields(clock.New(c.Control))
+	fields["board"] = g.Board()
+	fields["status"] = "started"
+	fields["winner"] = ""
+	fields["reason"] = ""
+	fields["next_board"] = g.Next()
+	fields["sub_boards"] = g.SubBoards()
+	fields["last_move"] = -1
+	fields["undo_request"] = ""
+	fields["draw_offer"] = ""
+
+	first, mark := chooseSides(roomInfo)
+	fields["first"] = first
+	fields["first_mark"] = mark.String()
+	fields["turn"] = first
+
+	now := time.Now()
+	fields["move_deadline"] = nextMoveDeadline(roomInfo, first, now)
+	fields["game_id"] = uuid.New().String()
+	fields["started_at"] = now.UnixMilli()
+	return fields, nil
+}
+
+// rematch начинает новую партию в той же комнате: поле очищается, стороны
+// выбираются заново, а после решённой серии начинается новая
+func (h *WebSocketHandler) rematch(ctx context.Context, roomID string, roomInfo map[string]string) error {
+	updates, err := newGameFields(roomInfo)
+	if err != nil {
+		return err
+	}
+	updates["rematch_offer"] = ""
+
+	if seriesDecided(roomInfo) {
+		updates["series_status"] = "ongoing"
+		updates["series_winner"] = ""
+		updates["series_wins1"] = 0
+		updates["series_wins2"] = 0
+		updates["series_draws"] = 0
+	}
+	// Новая партия начинается с пустой истории; дважды принятое предложение реванша
+	// получит ErrConflict и не сотрёт уже начатую партию
+	if _, err := h.Repo.UpdateRoom(roomID, withDeadline(repository.RoomUpdate{
+		Version:   roomInfo["version"],
+		State:     repository.StateStarted,
+		Fields:    updates,
+		TrimMoves: true,
+	}, roomInfo)); err != nil {
+		return err
+	}
+	if err := h.Repo.DeleteReport(roomID); err != nil {
+		return err
+	}
+
+	updatedRoomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch updated room info: %w", err)
+	}
+	h.BroadcastMessage(ctx, roomID, "rematch", withFields(updatedRoomInfo, map[string]interface{}{
+		"series": readSeries(updatedRoomInfo),
+		"clock":  clockView(updatedRoomInfo, time.Now()),
+	}))
+
+	// После смены сторон первым может ходить бот
+	if updates["first"] == bot.Name {
+		go h.playBot(roomID)
+	}
+	return nil
+}
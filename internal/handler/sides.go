@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"math/rand/v2"
+	"tic_tac_toe/internal/game"
+)
+
+// Кто ходит первым в очередной партии комнаты
+const (
+	FirstAlternate = "alternate" // В первой партии создатель, дальше по очереди
+	FirstCreator   = "creator"
+	FirstJoiner    = "joiner"
+	FirstRandom    = "random"
+	FirstLoser     = "loser" // Проигравший прошлую партию, после ничьей — по очереди
+)
+
+// chooseSides решает, кто ходит первым в следующей партии и каким знаком.
+// Если создатель выбрал знак (поле creator_mark), он играет им во всех
+// партиях, иначе первый ходит крестиками. Прошлая партия учитывается,
+// только если она закончена.
+func chooseSides(roomInfo map[string]string) (string, game.Mark) {
+	creator, joiner := roomInfo["user1"], roomInfo["user2"]
+	previous := gameFinished(roomInfo)
+	prevFirst, _ := seats(roomInfo)
+	other := func(user string) string {
+		if user == creator {
+			return joiner
+		}
+		return creator
+	}
+
+	first := creator
+	switch roomInfo["first_move"] {
+	case FirstCreator:
+	case FirstJoiner:
+		first = joiner
+	case FirstRandom:
+		if rand.IntN(2) == 1 {
+			first = joiner
+		}
+	case FirstLoser:
+		switch {
+		case previous && roomInfo["winner"] != "":
+			first = other(roomInfo["winner"])
+		case previous:
+			first = other(prevFirst)
+		}
+	default:
+		if previous {
+			first = other(prevFirst)
+		}
+	}
+
+	mark := game.X
+	if m, err := game.ParseMark(roomInfo["creator_mark"]); err == nil {
+		mark = m
+		if first != creator {
+			mark = m.Opponent()
+		}
+	}
+	return first, mark
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/game"
+	"tic_tac_toe/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// processUndo обрабатывает просьбы вернуть ход. Просьба хранится в комнате
+// в поле undo_request до ответа соперника или до следующего хода.
+func (h *WebSocketHandler) processUndo(ctx context.Context, roomID, player, action string) error {
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch room info: %w", err)
+	}
+	if player == "" || (player != roomInfo["user1"] && player != roomInfo["user2"]) {
+		return fmt.Errorf("you are not a player in this room")
+	}
+	pending := roomInfo["undo_request"]
+
+	// Сдачу и ничью по соглашению вернуть нельзя, только ход, которым закончилась партия
+	if gameFinished(roomInfo) && roomInfo["reason"] != ReasonLine && roomInfo["reason"] != ReasonBoardFull {
+		return game.ErrGameOver
+	}
+
+	switch action {
+	case "request_undo":
+		if pending != "" {
+			return fmt.Errorf("undo already requested")
+		}
+		history, err := h.Repo.GetMoves(roomID)
+		if err != nil {
+			return err
+		}
+		if undoPoint(history, player) < 0 {
+			return fmt.Errorf("no move to undo")
+		}
+
+		// Бот и комнаты с автоподтверждением соглашаются сразу
+		if roomInfo["auto_undo"] == "true" || h.getNextPlayer(roomInfo, player) == bot.Name {
+			return h.undo(ctx, roomID, roomInfo, player)
+		}
+		if _, err := h.Repo.UpdateRoom(roomID, repository.RoomUpdate{
+			Version: roomInfo["version"],
+			Fields:  map[string]interface{}{"undo_request": player},
+		}); err != nil {
+			return err
+		}
+		h.BroadcastMessage(ctx, roomID, "undo_requested", map[string]string{"player": player})
+
+	case "accept_undo":
+		if pending == "" || pending == player {
+			return fmt.Errorf("no undo request to accept")
+		}
+		return h.undo(ctx, roomID, roomInfo, pending)
+
+	case "decline_undo":
+		if pending == "" || pending == player {
+			return fmt.Errorf("no undo request to decline")
+		}
+		if _, err := h.Repo.UpdateRoom(roomID, repository.RoomUpdate{
+			Version: roomInfo["version"],
+			Fields:  map[string]interface{}{"undo_request": ""},
+		}); err != nil {
+			return err
+		}
+		h.BroadcastMessage(ctx, roomID, "undo_declined", map[string]string{"player": pending})
+	}
+	return nil
+}
+
+// undo возвращает партию к позиции перед последним ходом игрока.
+// Если соперник уже ответил, его ответ тоже отменяется.
+func (h *WebSocketHandler) undo(ctx context.Context, roomID string, roomInfo map[string]string, player string) error {
+	history, err := h.Repo.GetMoves(roomID)
+	if err != nil {
+		return err
+	}
+	keep := undoPoint(history, player)
+	if keep < 0 {
+		return fmt.Errorf("no move to undo")
+	}
+
+	cfg, err := gameConfig(roomInfo)
+	if err != nil {
+		return err
+	}
+	g, err := replay(cfg, history[:keep])
+	if err != nil {
+		return fmt.Errorf("failed to replay game: %w", err)
+	}
+
+	lastMove := -1
+	if keep > 0 {
+		lastMove = history[keep-1].Position
+	}
+	turn := userOf(roomInfo, g.Turn())
+	updates := map[string]interface{}{
+		"board":        g.Board(),
+		"turn":         turn,
+		"next_board":   g.Next(),
+		"sub_boards":   g.SubBoards(),
+		"last_move":    lastMove,
+		"undo_request": "",
+	}
+
+	// Время идёт у того, чья теперь очередь; до первого хода часы стоят
+	c, err := readClock(roomInfo)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	c.Stop(now)
+	if keep > 0 {
+		c.Start(clockSide(roomInfo, turn), now)
+	}
+	for field, value := range clockFields(c) {
+		updates[field] = value
+	}
+	updates["move_deadline"] = nextMoveDeadline(roomInfo, turn, now)
+
+	// Отменённый ход мог закончить партию: тогда она продолжается, а итог серии и разбор устаревают
+	if gameFinished(roomInfo) {
+		for field, value := range unscoreSeries(roomInfo) {
+			updates[field] = value
+		}
+		updates["status"] = "ongoing"
+		updates["winner"] = ""
+		updates["reason"] = ""
+		// Продолженная партия закончится иначе: новый game_id не даст запоздавшему
+		// разбору прежнего окончания попасть в неё
+		updates["game_id"] = uuid.New().String()
+	}
+
+	// История обрезается вместе с откатом доски, иначе между ними мог бы вклиниться ход
+	update := repository.RoomUpdate{
+		Version:   roomInfo["version"],
+		Fields:    updates,
+		TrimMoves: true,
+		KeepMoves: keep,
+	}
+	if gameFinished(roomInfo) {
+		update.State = repository.StateStarted
+	}
+	if _, err := h.Repo.UpdateRoom(roomID, withDeadline(update, roomInfo)); err != nil {
+		return err
+	}
+	if gameFinished(roomInfo) {
+		if err := h.Repo.DeleteReport(roomID); err != nil {
+			return err
+		}
+		// Партия снова идёт: в архив она попадёт, когда закончится заново
+		if h.Archive != nil {
+			if err := h.Archive.Delete(ctx, gameRecord(roomID, roomInfo, nil, time.Now()).ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	updatedRoomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch updated room info: %w", err)
+	}
+	state, err := roomState(h.Repo, roomID, updatedRoomInfo)
+	if err != nil {
+		return err
+	}
+	h.BroadcastMessage(ctx, roomID, "undo_accepted", state)
+	return nil
+}
+
+// undoPoint возвращает, сколько ходов истории останется после отмены
+// последнего хода игрока, или -1, если игрок ещё не ходил
+func undoPoint(history []repository.Move, player string) int {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Player == player {
+			return i
+		}
+	}
+	return -1
+}
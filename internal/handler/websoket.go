@@ -3,12 +3,16 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"sync"
+	"tic_tac_toe/internal/archive"
+	"tic_tac_toe/internal/bot"
+	"tic_tac_toe/internal/game"
 	"tic_tac_toe/internal/repository"
 	"tic_tac_toe/pkg/logger"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
@@ -21,10 +25,12 @@ var upgrader = websocket.Upgrader{
 }
 
 type WebSocketHandler struct {
-	Repo    *repository.RoomRepository
+	Repo    repository.RoomStore
 	Logger  logger.Logger
 	Mutex   sync.Mutex
 	Clients map[string][]*websocket.Conn // Список соединений для каждой комнаты
+	Bots    *bot.Pool                    // Движки ботов-соперников по комнатам
+	Archive *archive.Archive             // Архив законченных партий, nil — отключён
 }
 
 // HandleConnection обрабатывает WebSocket соединение
@@ -41,12 +47,20 @@ func (h *WebSocketHandler) HandleConnection(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Room not found"})
 	}
 
-	// Проверяем, что статус комнаты "started"
-	if roomInfo["status"] != "started" {
+	// Подключиться можно к идущей партии, в том числе после первого хода, и к
+	// законченной: в ней ещё можно вернуть ход или начать реванш
+	if state := repository.StateOf(roomInfo); state != repository.StateStarted && state != repository.StateFinished {
 		h.Logger.Error(c.Request().Context(), "Room is not started")
 		return echo.NewHTTPError(http.StatusBadRequest, "Room is not started")
 	}
 
+	// История ходов нужна клиентам, подключившимся посреди партии
+	state, err := roomState(h.Repo, roomID, roomInfo)
+	if err != nil {
+		h.Logger.Error(c.Request().Context(), fmt.Sprintf("Failed to load move history for room %s: %s", roomID, err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load move history"})
+	}
+
 	// Проверяем количество соединений
 	h.Mutex.Lock()
 	if len(h.Clients[roomID]) >= 2 {
@@ -66,11 +80,14 @@ func (h *WebSocketHandler) HandleConnection(c echo.Context) error {
 	h.Mutex.Lock()
 	h.Clients[roomID] = append(h.Clients[roomID], conn)
 	h.Mutex.Unlock()
+	if err := h.Repo.TouchRoom(roomID); err != nil {
+		h.Logger.Error(c.Request().Context(), fmt.Sprintf("Failed to touch room %s: %s", roomID, err.Error()))
+	}
 
-	// Отправляем начальное состояние комнаты новому клиенту
+	// Отправляем начальное состояние комнаты новому клиенту вместе с историей ходов
 	initialState := map[string]interface{}{
 		"type": "initial_state",
-		"data": roomInfo,
+		"data": state,
 	}
 	if err := conn.WriteJSON(initialState); err != nil {
 		h.Logger.Error(c.Request().Context(), fmt.Sprintf("Failed to send initial state to room %s: %s", roomID, err.Error()))
@@ -87,6 +104,11 @@ func (h *WebSocketHandler) HandleConnection(c echo.Context) error {
 		h.Mutex.Unlock()
 	}()
 
+	// Если первым ходит бот, он начинает, как только подключился соперник
+	if roomInfo["turn"] == bot.Name {
+		go h.playBot(roomID)
+	}
+
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
@@ -102,63 +124,194 @@ func (h *WebSocketHandler) HandleConnection(c echo.Context) error {
 			continue
 		}
 
-		if data["action"] == "make_move" {
-			if err := h.processMove(c.Request().Context(), roomID, data["player"], data["position"]); err != nil {
+		// Любое сообщение игрока продлевает жизнь комнаты, даже если ничего в ней не меняет
+		if err := h.Repo.TouchRoom(roomID); err != nil {
+			h.Logger.Error(c.Request().Context(), fmt.Sprintf("Failed to touch room %s: %s", roomID, err.Error()))
+		}
+
+		switch data["action"] {
+		case "make_move":
+			if err := h.processMove(c.Request().Context(), roomID, data["player"], data); err != nil {
 				h.Logger.Error(c.Request().Context(), fmt.Sprintf("Move error: %s", err.Error()))
+				// Проигравший гонку ход касается только того, кто его отправил
+				if errors.Is(err, repository.ErrConflict) {
+					h.SendMessage(c.Request().Context(), conn, "error", map[string]string{
+						"message": err.Error(),
+						"code":    "conflict",
+					})
+					continue
+				}
 				h.BroadcastMessage(c.Request().Context(), roomID, "error", map[string]string{
 					"message": err.Error(),
 				})
 			}
+		case "request_hint":
+			// Анализ длится до BOT_THINK_TIME, цикл чтения тем временем принимает ходы;
+			// контекст запроса отменяется, когда соединение закрывается
+			go h.processHint(c.Request().Context(), roomID, conn)
+		case "resign", "offer_draw", "accept_draw", "decline_draw":
+			if err := h.processFinish(c.Request().Context(), roomID, data["player"], data["action"], conn); err != nil {
+				h.Logger.Error(c.Request().Context(), fmt.Sprintf("Finish error: %s", err.Error()))
+				h.SendMessage(c.Request().Context(), conn, "error", map[string]string{
+					"message": err.Error(),
+				})
+			}
+		case "offer_rematch", "accept_rematch":
+			if err := h.processRematch(c.Request().Context(), roomID, data["player"], data["action"]); err != nil {
+				h.Logger.Error(c.Request().Context(), fmt.Sprintf("Rematch error: %s", err.Error()))
+				h.SendMessage(c.Request().Context(), conn, "error", map[string]string{
+					"message": err.Error(),
+				})
+			}
+		case "request_undo", "accept_undo", "decline_undo":
+			if err := h.processUndo(c.Request().Context(), roomID, data["player"], data["action"]); err != nil {
+				h.Logger.Error(c.Request().Context(), fmt.Sprintf("Undo error: %s", err.Error()))
+				h.SendMessage(c.Request().Context(), conn, "error", map[string]string{
+					"message": err.Error(),
+				})
+			}
 		}
 	}
 	return nil
 }
 
-func (h *WebSocketHandler) processMove(ctx context.Context, roomID, player, position string) error {
+func (h *WebSocketHandler) processMove(ctx context.Context, roomID, player string, data map[string]string) error {
+	return h.playMove(ctx, roomID, player, data, false)
+}
+
+// playMove проверяет и записывает ход. forced — ход, который сервер делает
+// за игрока, не успевшего походить: только ему срок хода не помеха.
+func (h *WebSocketHandler) playMove(ctx context.Context, roomID, player string, data map[string]string, forced bool) error {
 	roomInfo, err := h.Repo.GetRoomInfo(roomID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch room info: %w", err)
 	}
 
+	// Партия могла закончиться без хода: сдачей или ничьей по соглашению
+	if gameFinished(roomInfo) {
+		return game.ErrGameOver
+	}
+	if repository.StateOf(roomInfo) != repository.StateStarted {
+		return fmt.Errorf("game is not started")
+	}
+
 	// Проверяем текущего игрока
 	if roomInfo["turn"] != player {
 		return fmt.Errorf("it's not your turn")
 	}
 
-	// Проверяем доску и позицию
-	board := roomInfo["board"]
-	pos, err := strconv.Atoi(position)
-	if err != nil || pos < 0 || pos >= len(board) {
-		return fmt.Errorf("invalid position")
+	// Ход после падения флага не засчитывается, даже если сервер ещё не успел его заметить
+	c, err := readClock(roomInfo)
+	if err != nil {
+		return fmt.Errorf("failed to restore clock: %w", err)
+	}
+	now := time.Now()
+	if c.Running == clockSide(roomInfo, player) && c.Flagged(now) {
+		if err := h.flagFall(ctx, roomID, roomInfo, c); err != nil {
+			return err
+		}
+		return fmt.Errorf("time is up")
+	}
+	// Так же не засчитывается ход после срока хода: его сделает за игрока сервер
+	if deadline := moveDeadline(roomInfo); !forced && !deadline.IsZero() && !now.Before(deadline) {
+		return fmt.Errorf("time is up")
 	}
 
-	if board[pos] != ' ' {
-		return fmt.Errorf("position already occupied")
+	g, err := loadGame(roomInfo)
+	if err != nil {
+		return fmt.Errorf("failed to restore game: %w", err)
 	}
 
-	// Обновляем доску
-	playerSymbol := "X"
-	if player == roomInfo["user2"] {
-		playerSymbol = "O"
+	pos, err := parsePosition(g, data)
+	if err != nil {
+		return err
+	}
+
+	// Знак можно не указывать, если правила разрешают игроку только один
+	move := game.Move{Position: pos, Mark: game.Empty}
+	if data["mark"] != "" {
+		if move.Mark, err = game.ParseMark(data["mark"]); err != nil {
+			return err
+		}
+	}
+
+	result, err := g.Apply(move)
+	if err != nil {
+		return err
 	}
-	board = board[:pos] + playerSymbol + board[pos+1:]
 
 	// Проверяем победителя или ничью
 	status := "ongoing"
-	winner := h.checkWinner(board)
-	if winner != "" {
+	winner := ""
+	reason := ""
+	switch result.Outcome {
+	case game.Win:
 		status = "finished"
-	} else if !contains(board, ' ') {
+		winner = userOf(roomInfo, result.Winner)
+		reason = resultReason(result)
+	case game.Draw:
 		status = "tie"
+		reason = resultReason(result)
+	}
+
+	// Списываем время хода и запускаем часы соперника; после последнего хода часы стоят
+	c.Press(clockSide(roomInfo, player), now)
+	if result.Finished() {
+		c.Stop(now)
+	}
+
+	// Время на следующий ход отсчитывается, только пока партия идёт
+	next := h.getNextPlayer(roomInfo, player)
+	var deadline int64
+	if !result.Finished() {
+		deadline = nextMoveDeadline(roomInfo, next, now)
 	}
 
 	// Обновляем данные в репозитории
-	h.Repo.UpdateRoomField(roomID, map[string]interface{}{
-		"board":  board,
-		"turn":   h.getNextPlayer(roomInfo, player),
-		"status": status,
-		"winner": winner,
-	})
+	updates := map[string]interface{}{
+		"board":      g.Board(),
+		"turn":       next,
+		"status":     status,
+		"winner":     winner, // Имя победителя: в некоторых правилах оба игрока ставят один знак
+		"reason":     reason, // Как закончилась партия: line, board_full, resignation, agreement или timeout
+		"next_board": g.Next(),
+		"sub_boards": g.SubBoards(),
+		"last_move":  pos, // Клетка последнего хода, в том числе место падения фишки
+		// Новый ход отменяет неотвеченные просьбу вернуть ход и предложение ничьей
+		"undo_request": "",
+		"draw_offer":   "",
+		// Срок следующего хода, Unix-время в миллисекундах, 0 — без ограничения
+		"move_deadline": deadline,
+	}
+	for field, value := range clockFields(c) {
+		updates[field] = value
+	}
+	if result.Finished() {
+		for field, value := range scoreSeries(roomInfo, status, winner) {
+			updates[field] = value
+		}
+	}
+
+	// Ход и новое состояние записываются вместе и только если комнату не изменили
+	// после чтения: из двух одновременных ходов второй получит ErrConflict.
+	// По истории клиенты восстанавливают ход партии, а бот её разбирает.
+	record := repository.Move{
+		Player:   player,
+		Position: pos,
+		Mark:     game.Mark(g.Board()[pos]).String(),
+		Time:     now.UTC(),
+	}
+	change := repository.RoomUpdate{
+		Version: roomInfo["version"],
+		Fields:  updates,
+		Move:    &record,
+	}
+	if result.Finished() {
+		change.State = repository.StateFinished
+	}
+	if record.Seq, err = h.Repo.UpdateRoom(roomID, withDeadline(change, roomInfo)); err != nil {
+		return err
+	}
 
 	// Отправляем обновления клиентам
 	updatedRoomInfo, err := h.Repo.GetRoomInfo(roomID)
@@ -166,10 +319,57 @@ func (h *WebSocketHandler) processMove(ctx context.Context, roomID, player, posi
 		return fmt.Errorf("failed to fetch updated room info: %w", err)
 	}
 
-	h.BroadcastMessage(ctx, roomID, "update", updatedRoomInfo)
+	update := withFields(updatedRoomInfo, map[string]interface{}{
+		"move":  record,
+		"clock": clockView(updatedRoomInfo, time.Now()),
+	})
+	h.BroadcastMessage(ctx, roomID, "update", update)
+
+	// Если следующим ходит бот, отвечаем за него, не блокируя соединение игрока
+	if status != "ongoing" {
+		h.gameOver(ctx, roomID, updatedRoomInfo)
+	} else if updatedRoomInfo["turn"] == bot.Name {
+		go h.playBot(roomID)
+	}
 	return nil
 }
 
+// processHint отправляет оценки ходов только запросившему клиенту.
+// Ошибка тоже уходит только ему.
+func (h *WebSocketHandler) processHint(ctx context.Context, roomID string, conn *websocket.Conn) {
+	analysis, err := h.hint(ctx, roomID)
+	if err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Hint error: %s", err.Error()))
+		h.SendMessage(ctx, conn, "error", map[string]string{
+			"message": err.Error(),
+		})
+		return
+	}
+	h.SendMessage(ctx, conn, "hint", analysis)
+}
+
+func (h *WebSocketHandler) hint(ctx context.Context, roomID string) (map[string]interface{}, error) {
+	roomInfo, err := h.Repo.GetRoomInfo(roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch room info: %w", err)
+	}
+	return analyseRoom(ctx, h.Bots.Config(), roomInfo)
+}
+
+// SendMessage отправляет сообщение одному клиенту
+func (h *WebSocketHandler) SendMessage(ctx context.Context, conn *websocket.Conn, messageType string, data interface{}) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	response := map[string]interface{}{
+		"type": messageType,
+		"data": data,
+	}
+	if err := conn.WriteJSON(response); err != nil {
+		h.Logger.Error(ctx, fmt.Sprintf("Failed to send %s message: %s", messageType, err.Error()))
+	}
+}
+
 // BroadcastMessage отправляет сообщение всем клиентам в комнате
 func (h *WebSocketHandler) BroadcastMessage(ctx context.Context, roomID string, messageType string, data interface{}) {
 	h.Mutex.Lock()
@@ -232,34 +432,9 @@ func (h *WebSocketHandler) disconnectRoom(roomID string, conn *websocket.Conn) {
 	}
 }
 
-func (h *WebSocketHandler) checkWinner(board string) string {
-	winPatterns := [][]int{
-		{0, 1, 2}, {3, 4, 5}, {6, 7, 8}, // горизонтальные линии
-		{0, 3, 6}, {1, 4, 7}, {2, 5, 8}, // вертикальные линии
-		{0, 4, 8}, {2, 4, 6}, // диагонали
-	}
-
-	for _, pattern := range winPatterns {
-		if board[pattern[0]] != ' ' && board[pattern[0]] == board[pattern[1]] && board[pattern[1]] == board[pattern[2]] {
-			return string(board[pattern[0]])
-		}
-	}
-	return ""
-}
-
 func (h *WebSocketHandler) getNextPlayer(roomInfo map[string]string, currentPlayer string) string {
 	if currentPlayer == roomInfo["user1"] {
 		return roomInfo["user2"]
 	}
 	return roomInfo["user1"]
 }
-
-// Проверка на наличие свободных клеток (для ничьей)
-func contains(board string, char rune) bool {
-	for _, c := range board {
-		if c == char {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,83 @@
+package repository
+
+import "time"
+
+// TTL — сколько комната живёт без активности в каждом состоянии; 0 — вечно.
+// Срок продлевается каждым изменением комнаты и вызовом TouchRoom.
+type TTL struct {
+	Waiting  time.Duration `env:"ROOM_TTL_WAITING" env-default:"10m"`
+	Ready    time.Duration `env:"ROOM_TTL_READY" env-default:"10m"`
+	Started  time.Duration `env:"ROOM_TTL_STARTED" env-default:"1h"`
+	Finished time.Duration `env:"ROOM_TTL_FINISHED" env-default:"24h"`
+	Archived time.Duration `env:"ROOM_TTL_ARCHIVED" env-default:"24h"`
+}
+
+// For возвращает срок жизни комнаты в состоянии state
+func (t TTL) For(state RoomState) time.Duration {
+	switch state {
+	case StateWaiting:
+		return t.Waiting
+	case StateReady:
+		return t.Ready
+	case StateStarted:
+		return t.Started
+	case StateFinished:
+		return t.Finished
+	case StateArchived:
+		return t.Archived
+	}
+	return 0
+}
+
+// expiryArgs — аргументы Lua-скриптов для продления срока: текущий момент
+// и сроки состояний в миллисекундах в порядке ttlOrder из expiryLua
+func (t TTL) expiryArgs(now time.Time) []interface{} {
+	return []interface{}{
+		now.UnixMilli(),
+		t.Waiting.Milliseconds(),
+		t.Ready.Milliseconds(),
+		t.Started.Milliseconds(),
+		t.Finished.Milliseconds(),
+		t.Archived.Milliseconds(),
+	}
+}
+
+// Сроки жизни всех комнат лежат в одном отсортированном множестве, как и сроки
+// ходов: оценка — момент истечения в миллисекундах
+const expiriesKey = "expiries"
+
+// expiryLua — общие для скриптов функции: состояние комнаты, как в StateOf,
+// и продление её срока жизни по этому состоянию
+const expiryLua = `
+local function roomState(key)
+	local room = redis.call('HMGET', key, 'state', 'status', 'user2')
+	if room[1] and room[1] ~= '' then
+		return room[1]
+	end
+	if room[2] == 'started' or room[2] == 'ongoing' then
+		return 'started'
+	elseif room[2] == 'finished' or room[2] == 'tie' then
+		return 'finished'
+	elseif room[3] and room[3] ~= '' then
+		return 'ready'
+	end
+	return 'waiting'
+end
+
+local ttlOrder = {waiting = 1, ready = 2, started = 3, finished = 4, archived = 5}
+
+-- touch продлевает срок комнаты key; args[offset] — текущий момент,
+-- следующие пять — сроки состояний
+local function touch(key, expiries, state, args, offset)
+	local i = ttlOrder[state]
+	local ttl = 0
+	if i then
+		ttl = tonumber(args[offset + i])
+	end
+	if ttl > 0 then
+		redis.call('ZADD', expiries, tonumber(args[offset]) + ttl, key)
+	else
+		redis.call('ZREM', expiries, key)
+	end
+end
+`
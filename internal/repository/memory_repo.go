@@ -0,0 +1,384 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"tic_tac_toe/internal/game"
+	"time"
+)
+
+// MemoryRepository — хранилище комнат в памяти процесса. Ведёт себя так же,
+// как RoomRepository, но живёт, пока работает сервер, и не делится комнатами
+// с другими его экземплярами.
+type MemoryRepository struct {
+	mu        sync.Mutex
+	rooms     map[string]map[string]string
+	moves     map[string][]Move
+	reports   map[string]storedReport
+	deadlines map[string]time.Time
+	expiries  map[string]time.Time
+	ttl       TTL
+	watchers  map[int]func(roomID string, state RoomState) // Подписчики WatchExpired
+	nextWatch int
+}
+
+// storedReport — разбор партии вместе с game_id партии, которую разбирали
+type storedReport struct {
+	gameID string
+	report []byte
+}
+
+func NewMemoryRepository(ttl TTL) *MemoryRepository {
+	return &MemoryRepository{
+		rooms:     make(map[string]map[string]string),
+		moves:     make(map[string][]Move),
+		reports:   make(map[string]storedReport),
+		deadlines: make(map[string]time.Time),
+		expiries:  make(map[string]time.Time),
+		ttl:       ttl,
+		watchers:  make(map[int]func(string, RoomState)),
+	}
+}
+
+// formatValue приводит значение поля к строке так же, как это делает Redis-клиент
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// setFields записывает поля в комнату; вызывается под mu
+func setFields(room map[string]string, fields map[string]interface{}) {
+	for field, value := range fields {
+		room[field] = formatValue(value)
+	}
+}
+
+// Создать новую комнату
+func (repo *MemoryRepository) CreateRoom(roomID, admin string, cfg game.Config, settings map[string]interface{}) error {
+	roomData, err := newRoom(admin, cfg, settings)
+	if err != nil {
+		return err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	room := make(map[string]string, len(roomData))
+	setFields(room, roomData)
+	repo.rooms[roomID] = room
+	repo.touch(roomID, room)
+	return nil
+}
+
+// Присоединиться к комнате
+func (repo *MemoryRepository) JoinRoom(roomID, user string) error {
+	return joinRoom(repo, roomID, user)
+}
+
+// Получить информацию о комнате; возвращается копия, которую можно менять
+func (repo *MemoryRepository) GetRoomInfo(roomID string) (map[string]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	room, ok := repo.rooms[roomID]
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+	info := make(map[string]string, len(room))
+	for field, value := range room {
+		info[field] = value
+	}
+	return info, nil
+}
+
+// Получить комнаты в состоянии state, пустое состояние — все
+func (repo *MemoryRepository) ListRooms(state RoomState) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var rooms []string
+	for roomID, room := range repo.rooms {
+		if state == "" || StateOf(room) == state {
+			rooms = append(rooms, roomID)
+		}
+	}
+	sort.Strings(rooms)
+	return rooms, nil
+}
+
+// Удалить комнату
+func (repo *MemoryRepository) DeleteRoom(roomID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.deleteRoom(roomID)
+	return nil
+}
+
+// deleteRoom удаляет комнату со всеми её данными; вызывается под mu
+func (repo *MemoryRepository) deleteRoom(roomID string) {
+	delete(repo.rooms, roomID)
+	delete(repo.moves, roomID)
+	delete(repo.reports, roomID)
+	delete(repo.deadlines, roomID)
+	delete(repo.expiries, roomID)
+}
+
+// Удалить второго пользователя из комнаты
+func (repo *MemoryRepository) RemoveUser(roomID string) error {
+	return removeUser(repo, roomID)
+}
+
+// Покинуть комнату
+func (repo *MemoryRepository) LeaveRoom(roomID, user string) error {
+	return leaveRoom(repo, roomID, user)
+}
+
+// Начать игру
+func (repo *MemoryRepository) StartGame(roomID string, fields map[string]interface{}, deadline time.Time) error {
+	return startGame(repo, roomID, fields, deadline)
+}
+
+// Перенести законченную комнату в архив
+func (repo *MemoryRepository) ArchiveRoom(roomID string) error {
+	return archiveRoom(repo, roomID)
+}
+
+// Применить изменение комнаты атомарно, если с момента чтения её никто не менял.
+// Проверки те же, что в updateRoomScript у RoomRepository.
+func (repo *MemoryRepository) UpdateRoom(roomID string, u RoomUpdate) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	room, ok := repo.rooms[roomID]
+	if !ok {
+		return 0, ErrRoomNotFound
+	}
+
+	version := room["version"]
+	if version == "" {
+		version = "0"
+	}
+	if u.Version == "" {
+		u.Version = "0"
+	}
+	if u.Version != AnyVersion && u.Version != version {
+		return 0, ErrConflict
+	}
+	if u.State != "" {
+		if from := StateOf(room); !CanTransition(from, u.State) {
+			return 0, &TransitionError{From: from, To: u.State}
+		}
+		room["state"] = string(u.State)
+	}
+
+	moves := repo.moves[roomID]
+	if u.TrimMoves && u.KeepMoves < len(moves) {
+		moves = moves[:max(u.KeepMoves, 0)]
+	}
+	setFields(room, u.Fields)
+	if u.SetDeadline {
+		if u.Deadline.IsZero() {
+			delete(repo.deadlines, roomID)
+		} else {
+			repo.deadlines[roomID] = u.Deadline
+		}
+	}
+	n, _ := strconv.Atoi(version)
+	room["version"] = strconv.Itoa(n + 1)
+	repo.touch(roomID, room)
+
+	if u.Move != nil {
+		m := *u.Move
+		m.Seq = len(moves) + 1
+		moves = append(moves, m)
+	}
+	repo.moves[roomID] = moves
+	return len(moves), nil
+}
+
+// Получить историю ходов комнаты
+func (repo *MemoryRepository) GetMoves(roomID string) ([]Move, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return append(make([]Move, 0, len(repo.moves[roomID])), repo.moves[roomID]...), nil
+}
+
+// Сохранить разбор законченной партии gameID, если она всё ещё идёт в комнате
+func (repo *MemoryRepository) SaveReport(roomID, gameID string, report []byte) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	room, ok := repo.rooms[roomID]
+	if !ok || room["game_id"] != gameID {
+		return ErrConflict
+	}
+	repo.reports[roomID] = storedReport{gameID: gameID, report: append([]byte(nil), report...)}
+	return nil
+}
+
+// Получить сохранённый разбор партии gameID; nil, если его ещё нет
+func (repo *MemoryRepository) GetReport(roomID, gameID string) ([]byte, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	stored, ok := repo.reports[roomID]
+	if !ok || stored.gameID != gameID {
+		return nil, nil
+	}
+	return stored.report, nil
+}
+
+// Удалить разбор партии, когда он перестал соответствовать истории ходов
+func (repo *MemoryRepository) DeleteReport(roomID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	delete(repo.reports, roomID)
+	return nil
+}
+
+// Назначить комнате срок, к которому сервер должен проверить её сам
+func (repo *MemoryRepository) ScheduleDeadline(roomID string, at time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.deadlines[roomID] = at
+	return nil
+}
+
+// Снять срок комнаты; true, если срок был назначен
+func (repo *MemoryRepository) CancelDeadline(roomID string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	_, ok := repo.deadlines[roomID]
+	delete(repo.deadlines, roomID)
+	return ok, nil
+}
+
+// Захватить наступивший срок комнаты, перенеся его на now+lease
+func (repo *MemoryRepository) ClaimDeadline(roomID string, now time.Time, lease time.Duration) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	at, ok := repo.deadlines[roomID]
+	if !ok || at.After(now) {
+		return false, nil
+	}
+	repo.deadlines[roomID] = now.Add(lease)
+	return true, nil
+}
+
+// Получить комнаты, срок которых наступил к моменту now, в порядке сроков
+func (repo *MemoryRepository) DueDeadlines(now time.Time) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var rooms []string
+	for roomID, at := range repo.deadlines {
+		if !at.After(now) {
+			rooms = append(rooms, roomID)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return repo.deadlines[rooms[i]].Before(repo.deadlines[rooms[j]])
+	})
+	return rooms, nil
+}
+
+// touch продлевает срок жизни комнаты по её состоянию; вызывается под mu
+func (repo *MemoryRepository) touch(roomID string, room map[string]string) {
+	if ttl := repo.ttl.For(StateOf(room)); ttl > 0 {
+		repo.expiries[roomID] = time.Now().Add(ttl)
+	} else {
+		delete(repo.expiries, roomID)
+	}
+}
+
+// Продлить срок жизни комнаты: в ней что-то происходит
+func (repo *MemoryRepository) TouchRoom(roomID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if room, ok := repo.rooms[roomID]; ok {
+		repo.touch(roomID, room)
+	}
+	return nil
+}
+
+// Назначить срок жизни комнатам, у которых его нет
+func (repo *MemoryRepository) BackfillExpiries() (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	scored := 0
+	for roomID, room := range repo.rooms {
+		if _, ok := repo.expiries[roomID]; ok {
+			continue
+		}
+		repo.touch(roomID, room)
+		if _, ok := repo.expiries[roomID]; ok {
+			scored++
+		}
+	}
+	return scored, nil
+}
+
+// Получить комнаты, срок жизни которых истёк к моменту now
+func (repo *MemoryRepository) DueExpiries(now time.Time) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var rooms []string
+	for roomID, at := range repo.expiries {
+		if !at.After(now) {
+			rooms = append(rooms, roomID)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return repo.expiries[rooms[i]].Before(repo.expiries[rooms[j]])
+	})
+	return rooms, nil
+}
+
+// Удалить истёкшую комнату, если её не продлили после DueExpiries,
+// и сообщить об этом подписчикам WatchExpired
+func (repo *MemoryRepository) ExpireRoom(roomID string, now time.Time) (bool, error) {
+	repo.mu.Lock()
+	at, ok := repo.expiries[roomID]
+	if !ok || at.After(now) {
+		repo.mu.Unlock()
+		return false, nil
+	}
+	var state RoomState
+	if room, ok := repo.rooms[roomID]; ok {
+		state = StateOf(room)
+	}
+	repo.deleteRoom(roomID)
+	watchers := make([]func(string, RoomState), 0, len(repo.watchers))
+	for _, handle := range repo.watchers {
+		watchers = append(watchers, handle)
+	}
+	repo.mu.Unlock()
+
+	for _, handle := range watchers {
+		handle(roomID, state)
+	}
+	return true, nil
+}
+
+// Получать удалённые по сроку комнаты, пока не отменён ctx
+func (repo *MemoryRepository) WatchExpired(ctx context.Context, handle func(roomID string, state RoomState)) error {
+	repo.mu.Lock()
+	id := repo.nextWatch
+	repo.nextWatch++
+	repo.watchers[id] = handle
+	repo.mu.Unlock()
+
+	<-ctx.Done()
+	repo.mu.Lock()
+	delete(repo.watchers, id)
+	repo.mu.Unlock()
+	return nil
+}
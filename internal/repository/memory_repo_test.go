@@ -0,0 +1,417 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tic_tac_toe/internal/game"
+	"time"
+)
+
+var testTTL = TTL{
+	Waiting:  time.Minute,
+	Ready:    time.Minute,
+	Started:  time.Hour,
+	Finished: 24 * time.Hour,
+}
+
+// newTestRoom создаёт хранилище с одной комнатой 3x3 в состоянии waiting
+func newTestRoom(t *testing.T) (*MemoryRepository, string) {
+	t.Helper()
+	repo := NewMemoryRepository(testTTL)
+	roomID := RoomIDPrefix + "test"
+	if err := repo.CreateRoom(roomID, "alice", game.Config{}.WithDefaults(), nil); err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	return repo, roomID
+}
+
+func TestTransitions(t *testing.T) {
+	states := []RoomState{StateWaiting, StateReady, StateStarted, StateFinished, StateArchived}
+	allowed := map[[2]RoomState]bool{
+		{StateWaiting, StateReady}:     true,
+		{StateReady, StateWaiting}:     true,
+		{StateReady, StateStarted}:     true,
+		{StateStarted, StateFinished}:  true,
+		{StateFinished, StateStarted}:  true, // Реванш и отмена последнего хода
+		{StateFinished, StateWaiting}:  true, // Второй игрок ушёл после партии
+		{StateFinished, StateArchived}: true,
+	}
+
+	for _, from := range states {
+		for _, to := range states {
+			repo, roomID := newTestRoom(t)
+			repo.rooms[roomID]["state"] = string(from)
+
+			_, err := repo.UpdateRoom(roomID, RoomUpdate{Version: AnyVersion, State: to})
+			if allowed[[2]RoomState{from, to}] {
+				if err != nil {
+					t.Errorf("%s -> %s: %v", from, to, err)
+				}
+				if got := StateOf(repo.rooms[roomID]); got != to {
+					t.Errorf("%s -> %s: state %s", from, to, got)
+				}
+				continue
+			}
+
+			var te *TransitionError
+			if !errors.As(err, &te) || te.From != from || te.To != to {
+				t.Errorf("%s -> %s: err = %v, want TransitionError", from, to, err)
+			}
+			if !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("%s -> %s: err is not ErrInvalidTransition", from, to)
+			}
+			if got := StateOf(repo.rooms[roomID]); got != from {
+				t.Errorf("%s -> %s: rejected transition changed state to %s", from, to, got)
+			}
+		}
+	}
+}
+
+func TestRoomLifecycle(t *testing.T) {
+	repo, roomID := newTestRoom(t)
+	state := func() RoomState {
+		room, err := repo.GetRoomInfo(roomID)
+		if err != nil {
+			t.Fatalf("GetRoomInfo: %v", err)
+		}
+		return StateOf(room)
+	}
+
+	if err := repo.StartGame(roomID, nil, time.Time{}); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("start in waiting: err = %v", err)
+	}
+	if err := repo.JoinRoom(roomID, "alice"); !errors.Is(err, ErrNicknameTaken) {
+		t.Errorf("join with creator's name: err = %v", err)
+	}
+	if err := repo.JoinRoom(roomID, "bob"); err != nil || state() != StateReady {
+		t.Fatalf("join: err = %v, state %s", err, state())
+	}
+	if err := repo.JoinRoom(roomID, "carol"); !errors.Is(err, ErrRoomFull) {
+		t.Errorf("join full room: err = %v", err)
+	}
+	if err := repo.ArchiveRoom(roomID); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("archive before game: err = %v", err)
+	}
+	if err := repo.StartGame(roomID, map[string]interface{}{"turn": "bob"}, time.Time{}); err != nil || state() != StateStarted {
+		t.Fatalf("start: err = %v, state %s", err, state())
+	}
+	if err := repo.RemoveUser(roomID); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("remove user during game: err = %v", err)
+	}
+	if _, err := repo.UpdateRoom(roomID, RoomUpdate{Version: AnyVersion, State: StateFinished}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	if err := repo.ArchiveRoom(roomID); err != nil || state() != StateArchived {
+		t.Fatalf("archive: err = %v, state %s", err, state())
+	}
+
+	// Архивную комнату нельзя ни занять, ни начать в ней партию
+	var te *TransitionError
+	if err := repo.JoinRoom(roomID, "carol"); !errors.As(err, &te) {
+		t.Errorf("join archived room: err = %v", err)
+	}
+	if err := repo.StartGame(roomID, nil, time.Time{}); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("start archived room: err = %v", err)
+	}
+
+	room, _ := repo.GetRoomInfo(roomID)
+	if room["user2"] != "bob" || room["turn"] != "bob" || room["status"] != "started" {
+		t.Errorf("room = %v", room)
+	}
+}
+
+func TestVersionConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		version func(current string) string
+		err     error
+	}{
+		{"current version", func(v string) string { return v }, nil},
+		{"stale version", func(v string) string { return "0" }, ErrConflict},
+		{"unknown version", func(v string) string { return "" }, ErrConflict},
+		{"any version", func(v string) string { return AnyVersion }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, roomID := newTestRoom(t)
+			// Одно изменение, чтобы версия прочитанной при создании комнаты устарела
+			if _, err := repo.UpdateRoom(roomID, RoomUpdate{Version: "0"}); err != nil {
+				t.Fatalf("first update: %v", err)
+			}
+			room, _ := repo.GetRoomInfo(roomID)
+
+			_, err := repo.UpdateRoom(roomID, RoomUpdate{
+				Version: tt.version(room["version"]),
+				Fields:  map[string]interface{}{"turn": "bob"},
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			after, _ := repo.GetRoomInfo(roomID)
+			want, turn := "1", "alice"
+			if tt.err == nil {
+				want, turn = "2", "bob"
+			}
+			if after["version"] != want || after["turn"] != turn {
+				t.Errorf("version %s, turn %s; want %s, %s", after["version"], after["turn"], want, turn)
+			}
+		})
+	}
+}
+
+func TestMoves(t *testing.T) {
+	repo, roomID := newTestRoom(t)
+	for i, player := range []string{"alice", "bob", "alice"} {
+		seq, err := repo.UpdateRoom(roomID, RoomUpdate{
+			Version: AnyVersion,
+			Move:    &Move{Seq: 42, Player: player, Position: i},
+		})
+		if err != nil || seq != i+1 {
+			t.Fatalf("move %d: seq %d, err %v", i, seq, err)
+		}
+	}
+
+	// Отмена хода обрезает историю, следующий ход получает освободившийся номер
+	seq, err := repo.UpdateRoom(roomID, RoomUpdate{
+		Version:   AnyVersion,
+		TrimMoves: true,
+		KeepMoves: 1,
+		Move:      &Move{Player: "bob", Position: 8},
+	})
+	if err != nil || seq != 2 {
+		t.Fatalf("trim: seq %d, err %v", seq, err)
+	}
+	moves, _ := repo.GetMoves(roomID)
+	if len(moves) != 2 || moves[0].Position != 0 || moves[1].Position != 8 || moves[1].Seq != 2 {
+		t.Errorf("moves = %+v", moves)
+	}
+}
+
+func TestDeletedRoom(t *testing.T) {
+	repo, roomID := newTestRoom(t)
+	if err := repo.DeleteRoom(roomID); err != nil {
+		t.Fatalf("DeleteRoom: %v", err)
+	}
+
+	// Запоздавшие изменения не возвращают удалённую комнату
+	if _, err := repo.UpdateRoom(roomID, RoomUpdate{Version: AnyVersion, Fields: map[string]interface{}{"turn": "bob"}}); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("UpdateRoom: err = %v", err)
+	}
+	if err := repo.JoinRoom(roomID, "bob"); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("JoinRoom: err = %v", err)
+	}
+	if err := repo.TouchRoom(roomID); err != nil {
+		t.Errorf("TouchRoom: %v", err)
+	}
+	if _, err := repo.GetRoomInfo(roomID); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("GetRoomInfo: err = %v", err)
+	}
+	if due, _ := repo.DueExpiries(time.Now().Add(48 * time.Hour)); len(due) != 0 {
+		t.Errorf("expiries of deleted room: %v", due)
+	}
+}
+
+func TestExpireRoom(t *testing.T) {
+	repo, roomID := newTestRoom(t)
+	now := time.Now()
+	expired := now.Add(testTTL.Waiting + time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan RoomState, 2)
+	go repo.WatchExpired(ctx, func(id string, state RoomState) {
+		if id == roomID {
+			events <- state
+		}
+	})
+	// Подписка регистрируется в горутине, ждём её до первого удаления
+	for watching := false; !watching; {
+		repo.mu.Lock()
+		watching = len(repo.watchers) > 0
+		repo.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+
+	if due, _ := repo.DueExpiries(now); len(due) != 0 {
+		t.Errorf("due before TTL: %v", due)
+	}
+	if due, _ := repo.DueExpiries(expired); len(due) != 1 || due[0] != roomID {
+		t.Errorf("due after TTL: %v", due)
+	}
+	if ok, _ := repo.ExpireRoom(roomID, now); ok {
+		t.Error("expired a room before its TTL")
+	}
+
+	// Комнату продлили после DueExpiries: срок снова в будущем, удалять её нельзя
+	if err := repo.JoinRoom(roomID, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.StartGame(roomID, nil, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := repo.ExpireRoom(roomID, expired); ok {
+		t.Error("expired a room extended by its new state")
+	}
+	if _, err := repo.GetRoomInfo(roomID); err != nil {
+		t.Fatalf("extended room was deleted: %v", err)
+	}
+
+	late := time.Now().Add(testTTL.Started + time.Second)
+	if ok, _ := repo.ExpireRoom(roomID, late); !ok {
+		t.Fatal("failed to expire a room")
+	}
+	if ok, _ := repo.ExpireRoom(roomID, late); ok {
+		t.Error("expired the same room twice")
+	}
+	if _, err := repo.GetRoomInfo(roomID); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("expired room still exists: %v", err)
+	}
+	select {
+	case state := <-events:
+		if state != StateStarted {
+			t.Errorf("expired in state %s", state)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watcher was not notified")
+	}
+	if len(events) != 0 {
+		t.Error("watcher notified twice")
+	}
+}
+
+func TestBackfillExpiries(t *testing.T) {
+	repo, roomID := newTestRoom(t)
+	// Комната из времён, когда сроков жизни ещё не было
+	delete(repo.expiries, roomID)
+	if due, _ := repo.DueExpiries(time.Now().Add(48 * time.Hour)); len(due) != 0 {
+		t.Fatalf("due without expiry: %v", due)
+	}
+
+	if n, err := repo.BackfillExpiries(); err != nil || n != 1 {
+		t.Fatalf("BackfillExpiries() = %d, %v", n, err)
+	}
+	at := repo.expiries[roomID]
+	if due, _ := repo.DueExpiries(time.Now().Add(testTTL.Waiting + time.Second)); len(due) != 1 || due[0] != roomID {
+		t.Errorf("due after backfill: %v", due)
+	}
+
+	// Уже назначенный срок повторный запуск не продлевает
+	if n, err := repo.BackfillExpiries(); err != nil || n != 0 {
+		t.Errorf("second BackfillExpiries() = %d, %v", n, err)
+	}
+	if !repo.expiries[roomID].Equal(at) {
+		t.Error("backfill extended a scheduled expiry")
+	}
+}
+
+func TestDeadlineClaims(t *testing.T) {
+	repo, roomID := newTestRoom(t)
+	at := time.Now().Add(time.Minute)
+	lease := 10 * time.Second
+	if err := repo.ScheduleDeadline(roomID, at); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := repo.ClaimDeadline(roomID, at.Add(-time.Second), lease); ok {
+		t.Error("claimed a deadline before it came")
+	}
+	if due, _ := repo.DueDeadlines(at); len(due) != 1 {
+		t.Errorf("due = %v", due)
+	}
+	if ok, _ := repo.ClaimDeadline(roomID, at, lease); !ok {
+		t.Fatal("failed to claim a due deadline")
+	}
+
+	// Пока срок захвачен, его не видят другие; необработанный срок наступает снова
+	if ok, _ := repo.ClaimDeadline(roomID, at, lease); ok {
+		t.Error("claimed the same deadline twice")
+	}
+	if due, _ := repo.DueDeadlines(at.Add(lease - time.Millisecond)); len(due) != 0 {
+		t.Errorf("due during lease: %v", due)
+	}
+	if ok, _ := repo.ClaimDeadline(roomID, at.Add(lease), lease); !ok {
+		t.Error("failed to reclaim a deadline after the lease")
+	}
+
+	if ok, _ := repo.CancelDeadline(roomID); !ok {
+		t.Error("CancelDeadline of a scheduled deadline returned false")
+	}
+	if ok, _ := repo.CancelDeadline(roomID); ok {
+		t.Error("CancelDeadline of a cancelled deadline returned true")
+	}
+}
+
+func TestDeadlineUpdates(t *testing.T) {
+	repo, roomID := newTestRoom(t)
+	at := time.Now().Add(time.Minute)
+	due := func() int {
+		rooms, _ := repo.DueDeadlines(at)
+		return len(rooms)
+	}
+
+	// Срок назначается той же записью, что и поля, и только если запись удалась
+	if _, err := repo.UpdateRoom(roomID, RoomUpdate{Version: "5", SetDeadline: true, Deadline: at}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("stale update: %v", err)
+	}
+	if due() != 0 {
+		t.Error("stale update scheduled a deadline")
+	}
+	if _, err := repo.UpdateRoom(roomID, RoomUpdate{Version: "0", SetDeadline: true, Deadline: at}); err != nil {
+		t.Fatal(err)
+	}
+	if due() != 1 {
+		t.Error("update did not schedule a deadline")
+	}
+
+	// Без SetDeadline срок не трогается, с нулевым Deadline снимается
+	if _, err := repo.UpdateRoom(roomID, RoomUpdate{Version: AnyVersion}); err != nil || due() != 1 {
+		t.Errorf("plain update: %v, due %d", err, due())
+	}
+	if _, err := repo.UpdateRoom(roomID, RoomUpdate{Version: AnyVersion, SetDeadline: true}); err != nil || due() != 0 {
+		t.Errorf("cancelling update: %v, due %d", err, due())
+	}
+}
+
+func TestReports(t *testing.T) {
+	repo, roomID := newTestRoom(t)
+	setGame := func(gameID string) {
+		t.Helper()
+		if _, err := repo.UpdateRoom(roomID, RoomUpdate{Version: AnyVersion, Fields: map[string]interface{}{"game_id": gameID}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setGame("first")
+	if err := repo.SaveReport(roomID, "first", []byte("[1]")); err != nil {
+		t.Fatalf("SaveReport: %v", err)
+	}
+	if report, _ := repo.GetReport(roomID, "first"); string(report) != "[1]" {
+		t.Errorf("report = %q", report)
+	}
+
+	// Разбор прошлой партии не достаётся новой и не сохраняется поверх неё
+	setGame("second")
+	if report, _ := repo.GetReport(roomID, "second"); report != nil {
+		t.Errorf("new game got report %q", report)
+	}
+	if err := repo.SaveReport(roomID, "first", []byte("[1]")); !errors.Is(err, ErrConflict) {
+		t.Errorf("late save: err = %v", err)
+	}
+	if report, _ := repo.GetReport(roomID, "second"); report != nil {
+		t.Errorf("late save landed: %q", report)
+	}
+
+	// Запоздавший разбор не переживает удалённую комнату
+	if err := repo.DeleteRoom(roomID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveReport(roomID, "second", []byte("[2]")); !errors.Is(err, ErrConflict) {
+		t.Errorf("save to deleted room: err = %v", err)
+	}
+	if len(repo.reports) != 0 {
+		t.Errorf("orphaned reports: %v", repo.reports)
+	}
+}
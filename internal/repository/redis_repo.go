@@ -1,152 +1,486 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"tic_tac_toe/internal/game"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
+// RoomRepository — хранилище комнат в Redis
 type RoomRepository struct {
 	rdb *redis.Client
 	ctx context.Context
+	ttl TTL
 }
 
-func NewRoomRepository(rdb *redis.Client, ctx context.Context) *RoomRepository {
+func NewRoomRepository(rdb *redis.Client, ctx context.Context, ttl TTL) *RoomRepository {
 	return &RoomRepository{
 		rdb: rdb,
 		ctx: ctx,
+		ttl: ttl,
 	}
 }
 
 // Создать новую комнату
-func (repo *RoomRepository) CreateRoom(roomID, admin string) error {
-	roomData := map[string]interface{}{
-		"user1":  admin,
-		"user2":  "",
-		"admin":  admin,
-		"status": "waiting",   // waiting, started, finished
-		"board":  "         ", // 9 пробелов для пустого поля
-		"turn":   admin,       // Хранит, чей сейчас ход
+func (repo *RoomRepository) CreateRoom(roomID, admin string, cfg game.Config, settings map[string]interface{}) error {
+	roomData, err := newRoom(admin, cfg, settings)
+	if err != nil {
+		return err
 	}
 
-	err := repo.rdb.HSet(repo.ctx, roomID, roomData).Err()
+	err = repo.rdb.HSet(repo.ctx, roomID, roomData).Err()
 	if err != nil {
 		return fmt.Errorf("failed to create room: %w", err)
 	}
 
-	return nil
+	return repo.TouchRoom(roomID)
 }
 
 // Присоединиться к комнате
 func (repo *RoomRepository) JoinRoom(roomID, user string) error {
+	return joinRoom(repo, roomID, user)
+}
+
+// Получить информацию о комнате
+func (repo *RoomRepository) GetRoomInfo(roomID string) (map[string]string, error) {
 	room, err := repo.rdb.HGetAll(repo.ctx, roomID).Result()
 	if err != nil || len(room) == 0 {
-		return fmt.Errorf("room not found")
+		return nil, ErrRoomNotFound
+	}
+	return room, nil
+}
+
+// Получить комнаты в состоянии state, пустое состояние — все. Ключи перебираются
+// через SCAN, чтобы не блокировать Redis; история и разбор партии пропускаются.
+func (repo *RoomRepository) ListRooms(state RoomState) ([]string, error) {
+	var rooms []string
+	iter := repo.rdb.Scan(repo.ctx, 0, RoomIDPrefix+"*", 100).Iterator()
+	for iter.Next(repo.ctx) {
+		roomID := iter.Val()
+		if strings.Contains(roomID, ":") {
+			continue
+		}
+		if state != "" {
+			room, err := repo.rdb.HGetAll(repo.ctx, roomID).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list rooms: %w", err)
+			}
+			if len(room) == 0 || StateOf(room) != state {
+				continue
+			}
+		}
+		rooms = append(rooms, roomID)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list rooms: %w", err)
 	}
+	sort.Strings(rooms)
+	return rooms, nil
+}
+
+// Удалить комнату
+func (repo *RoomRepository) DeleteRoom(roomID string) error {
+	err := repo.rdb.Del(repo.ctx, roomID, movesKey(roomID), reportKey(roomID)).Err()
+	if err != nil {
+		return fmt.Errorf("failed to delete room: %w", err)
+	}
+	if _, err := repo.CancelDeadline(roomID); err != nil {
+		return fmt.Errorf("failed to delete room: %w", err)
+	}
+	if err := repo.rdb.ZRem(repo.ctx, expiriesKey, roomID).Err(); err != nil {
+		return fmt.Errorf("failed to delete room: %w", err)
+	}
+	return nil
+}
+
+// Удалить второго пользователя из комнаты
+func (repo *RoomRepository) RemoveUser(roomID string) error {
+	return removeUser(repo, roomID)
+}
+
+// Покинуть комнату
+func (repo *RoomRepository) LeaveRoom(roomID, user string) error {
+	return leaveRoom(repo, roomID, user)
+}
+
+// Начать игру
+func (repo *RoomRepository) StartGame(roomID string, fields map[string]interface{}, deadline time.Time) error {
+	return startGame(repo, roomID, fields, deadline)
+}
+
+// Перенести законченную комнату в архив
+func (repo *RoomRepository) ArchiveRoom(roomID string) error {
+	return archiveRoom(repo, roomID)
+}
+
+// updateRoomScript применяет RoomUpdate, если версия комнаты не изменилась
+// и переход в новое состояние разрешён, и продлевает срок жизни комнаты.
+// KEYS: хеш комнаты, история ходов, сроки жизни комнат, сроки комнат; ARGV: версия,
+// сколько ходов оставить (-1 — все), JSON хода или пустая строка, новое состояние
+// или пустая строка, разрешённые исходные состояния через запятую, шесть аргументов
+// TTL.expiryArgs, срок комнаты в миллисекундах (пустая строка — не менять,
+// 0 — снять), затем пары поле-значение. Возвращает {длина истории после
+// изменения}, {-1} — комнаты нет, {-2} — конфликт версий, {-3, состояние} —
+// переход запрещён.
+var updateRoomScript = redis.NewScript(expiryLua + `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return {-1}
+end
+if ARGV[1] ~= '*' and (redis.call('HGET', KEYS[1], 'version') or '0') ~= ARGV[1] then
+	return {-2}
+end
+
+local state = roomState(KEYS[1])
+if ARGV[4] ~= '' then
+	if not string.find(',' .. ARGV[5] .. ',', ',' .. state .. ',', 1, true) then
+		return {-3, state}
+	end
+	state = ARGV[4]
+	redis.call('HSET', KEYS[1], 'state', state)
+end
 
-	if room["user2"] != "" {
-		return fmt.Errorf("room is full")
+local keep = tonumber(ARGV[2])
+if keep == 0 then
+	redis.call('DEL', KEYS[2])
+elseif keep > 0 then
+	redis.call('LTRIM', KEYS[2], 0, keep - 1)
+end
+if #ARGV > 12 then
+	redis.call('HSET', KEYS[1], unpack(ARGV, 13))
+end
+if ARGV[12] == '0' then
+	redis.call('ZREM', KEYS[4], KEYS[1])
+elseif ARGV[12] ~= '' then
+	redis.call('ZADD', KEYS[4], tonumber(ARGV[12]), KEYS[1])
+end
+redis.call('HINCRBY', KEYS[1], 'version', 1)
+touch(KEYS[1], KEYS[3], state, ARGV, 6)
+
+local seq = redis.call('LLEN', KEYS[2])
+if ARGV[3] ~= '' then
+	-- Номер хода вставляется первым полем JSON-записи
+	seq = seq + 1
+	redis.call('RPUSH', KEYS[2], '{"seq":' .. seq .. ',' .. string.sub(ARGV[3], 2))
+end
+return {seq}
+`)
+
+// Применить изменение комнаты атомарно, если с момента чтения её никто не менял.
+// Возвращает длину истории после изменения — для нового хода это его номер.
+func (repo *RoomRepository) UpdateRoom(roomID string, u RoomUpdate) (int, error) {
+	version := u.Version
+	if version == "" {
+		version = "0"
+	}
+	keep := -1
+	if u.TrimMoves {
+		keep = u.KeepMoves
+	}
+	var move []byte
+	if u.Move != nil {
+		m := *u.Move
+		m.Seq = 0
+		var err error
+		if move, err = json.Marshal(m); err != nil {
+			return 0, fmt.Errorf("failed to encode move: %w", err)
+		}
 	}
 
-	if room["user1"] == room["user2"] || room["user2"] == room["user1"] {
-		return fmt.Errorf("nickname already in use")
+	fields := make([]string, 0, len(u.Fields))
+	for field := range u.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	args := []interface{}{version, keep, move, string(u.State), sources(u.State)}
+	args = append(args, repo.ttl.expiryArgs(time.Now())...)
+	deadline := ""
+	if u.SetDeadline {
+		deadline = "0"
+		if !u.Deadline.IsZero() {
+			deadline = strconv.FormatInt(u.Deadline.UnixMilli(), 10)
+		}
+	}
+	args = append(args, deadline)
+	for _, field := range fields {
+		args = append(args, field, u.Fields[field])
 	}
 
-	// Присоединяем второго пользователя
-	err = repo.rdb.HSet(repo.ctx, roomID, "user2", user).Err()
+	res, err := updateRoomScript.Run(repo.ctx, repo.rdb, []string{roomID, movesKey(roomID), expiriesKey, deadlinesKey}, args...).Slice()
 	if err != nil {
-		return fmt.Errorf("failed to join room: %w", err)
+		return 0, fmt.Errorf("failed to update room: %w", err)
+	}
+	if len(res) == 0 {
+		return 0, fmt.Errorf("failed to update room: empty script reply")
+	}
+	n, _ := res[0].(int64)
+	switch n {
+	case -1:
+		return 0, ErrRoomNotFound
+	case -2:
+		return 0, ErrConflict
+	case -3:
+		from, _ := res[1].(string)
+		return 0, &TransitionError{From: RoomState(from), To: u.State}
 	}
+	return int(n), nil
+}
 
-	// Если игра уже началась, сразу устанавливаем статус
-	if room["status"] == "started" {
-		err = repo.rdb.HSet(repo.ctx, roomID, "status", "waiting").Err()
-		if err != nil {
-			return fmt.Errorf("failed to set room status to waiting: %w", err)
+// История ходов и разбор партии хранятся рядом с хешем комнаты
+func movesKey(roomID string) string  { return roomID + ":moves" }
+func reportKey(roomID string) string { return roomID + ":report" }
+
+// Получить историю ходов комнаты
+func (repo *RoomRepository) GetMoves(roomID string) ([]Move, error) {
+	items, err := repo.rdb.LRange(repo.ctx, movesKey(roomID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch moves: %w", err)
+	}
+
+	moves := make([]Move, len(items))
+	for i, item := range items {
+		if err := json.Unmarshal([]byte(item), &moves[i]); err != nil {
+			return nil, fmt.Errorf("failed to decode move: %w", err)
 		}
 	}
+	return moves, nil
+}
 
+// saveReportScript сохраняет разбор, только если в комнате всё ещё та партия,
+// которую разбирали: разбор считается долго, и за это время комнату могли
+// удалить или начать в ней новую партию. Разбор хранится вместе с game_id.
+// KEYS: хеш комнаты, разбор; ARGV: game_id, разбор.
+var saveReportScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'game_id') ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[1] .. '\n' .. ARGV[2])
+return 1
+`)
+
+// Сохранить разбор законченной партии gameID
+func (repo *RoomRepository) SaveReport(roomID, gameID string, report []byte) error {
+	n, err := saveReportScript.Run(repo.ctx, repo.rdb, []string{roomID, reportKey(roomID)}, gameID, report).Int()
+	if err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+	if n == 0 {
+		return ErrConflict
+	}
 	return nil
 }
 
-// Получить информацию о комнате
-func (repo *RoomRepository) GetRoomInfo(roomID string) (map[string]string, error) {
-	room, err := repo.rdb.HGetAll(repo.ctx, roomID).Result()
-	if err != nil || len(room) == 0 {
-		return nil, fmt.Errorf("room not found")
+// Получить сохранённый разбор партии gameID; nil, если его ещё нет
+func (repo *RoomRepository) GetReport(roomID, gameID string) ([]byte, error) {
+	value, err := repo.rdb.Get(repo.ctx, reportKey(roomID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
 	}
-	return room, nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report: %w", err)
+	}
+	// Разбор другой партии комнаты всё равно что отсутствует
+	id, report, ok := bytes.Cut(value, []byte("\n"))
+	if !ok || string(id) != gameID {
+		return nil, nil
+	}
+	return report, nil
 }
 
-// Удалить комнату
-func (repo *RoomRepository) DeleteRoom(roomID string) error {
-	err := repo.rdb.Del(repo.ctx, roomID).Err()
+// Удалить разбор партии, когда он перестал соответствовать истории ходов
+func (repo *RoomRepository) DeleteReport(roomID string) error {
+	err := repo.rdb.Del(repo.ctx, reportKey(roomID)).Err()
 	if err != nil {
-		return fmt.Errorf("failed to delete room: %w", err)
+		return fmt.Errorf("failed to delete report: %w", err)
 	}
 	return nil
 }
 
-// Удалить второго пользователя из комнаты
-func (repo *RoomRepository) RemoveUser(roomID string) error {
-	err := repo.rdb.HSet(repo.ctx, roomID, "user2", "").Err()
+// Сроки всех комнат лежат в одном отсортированном множестве: оценка — момент
+// срока в миллисекундах, поэтому просроченные комнаты находит любой экземпляр сервера
+const deadlinesKey = "deadlines"
+
+// Назначить комнате срок, к которому сервер должен проверить её сам
+func (repo *RoomRepository) ScheduleDeadline(roomID string, at time.Time) error {
+	err := repo.rdb.ZAdd(repo.ctx, deadlinesKey, &redis.Z{Score: float64(at.UnixMilli()), Member: roomID}).Err()
 	if err != nil {
-		return fmt.Errorf("failed to remove user: %w", err)
+		return fmt.Errorf("failed to schedule deadline: %w", err)
 	}
 	return nil
 }
 
-// Покинуть комнату
-func (repo *RoomRepository) LeaveRoom(roomID, user string) error {
-	room, err := repo.rdb.HGetAll(repo.ctx, roomID).Result()
-	if err != nil || len(room) == 0 {
-		return fmt.Errorf("room not found")
+// Снять срок комнаты; true, если срок был назначен
+func (repo *RoomRepository) CancelDeadline(roomID string) (bool, error) {
+	n, err := repo.rdb.ZRem(repo.ctx, deadlinesKey, roomID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel deadline: %w", err)
 	}
+	return n > 0, nil
+}
 
-	if room["user1"] == user {
-		// Если выходит создатель комнаты, удаляем всю комнату
-		return repo.DeleteRoom(roomID)
-	} else if room["user2"] == user {
-		// Если выходит второй игрок, просто очищаем user2
-		return repo.RemoveUser(roomID)
+// Получить комнаты, срок которых наступил к моменту now
+func (repo *RoomRepository) DueDeadlines(now time.Time) ([]string, error) {
+	rooms, err := repo.rdb.ZRangeByScore(repo.ctx, deadlinesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deadlines: %w", err)
 	}
-
-	return fmt.Errorf("user not found in room")
+	return rooms, nil
 }
 
-// Начать игру
-func (repo *RoomRepository) StartGame(roomID string) error {
-	fmt.Printf("StartGame called with roomID: %s\n", roomID)
+// claimDeadlineScript переносит наступивший срок на ARGV[2] + ARGV[3] миллисекунд.
+// Срок не снимается, пока его не обработали: если сервер упадёт посреди
+// обработки, по истечении отсрочки срок подхватит другой экземпляр.
+var claimDeadlineScript = redis.NewScript(`
+local at = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if at and tonumber(at) <= tonumber(ARGV[2]) then
+	redis.call('ZADD', KEYS[1], tonumber(ARGV[2]) + tonumber(ARGV[3]), ARGV[1])
+	return 1
+end
+return 0
+`)
 
-	room, err := repo.rdb.HGetAll(repo.ctx, roomID).Result()
+// Захватить наступивший срок комнаты на время lease; true получит только один экземпляр сервера
+func (repo *RoomRepository) ClaimDeadline(roomID string, now time.Time, lease time.Duration) (bool, error) {
+	n, err := claimDeadlineScript.Run(repo.ctx, repo.rdb, []string{deadlinesKey}, roomID, now.UnixMilli(), lease.Milliseconds()).Int()
 	if err != nil {
-		fmt.Printf("Error fetching room from Redis: %s\n", err)
-		return fmt.Errorf("room not found")
+		return false, fmt.Errorf("failed to claim deadline: %w", err)
 	}
+	return n == 1, nil
+}
 
-	if len(room) == 0 {
-		return fmt.Errorf("room not found")
-	}
+// touchRoomScript продлевает срок жизни существующей комнаты по её состоянию.
+// KEYS: хеш комнаты, сроки жизни комнат; ARGV: TTL.expiryArgs.
+var touchRoomScript = redis.NewScript(expiryLua + `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+touch(KEYS[1], KEYS[2], roomState(KEYS[1]), ARGV, 1)
+return 1
+`)
 
-	// Проверяем, что user2 есть и что текущий игрок - администратор
-	if room["user2"] == "" {
-		return fmt.Errorf("cannot start game, room is not full")
+// Продлить срок жизни комнаты: в ней что-то происходит
+func (repo *RoomRepository) TouchRoom(roomID string) error {
+	err := touchRoomScript.Run(repo.ctx, repo.rdb, []string{roomID, expiriesKey}, repo.ttl.expiryArgs(time.Now())...).Err()
+	if err != nil {
+		return fmt.Errorf("failed to touch room: %w", err)
 	}
+	return nil
+}
+
+// backfillExpiryScript назначает срок жизни комнате, у которой его ещё нет.
+// KEYS: хеш комнаты, сроки жизни комнат; ARGV: TTL.expiryArgs. Возвращает 1,
+// если срок назначен; комнаты бессрочных состояний остаются без срока.
+var backfillExpiryScript = redis.NewScript(expiryLua + `
+if redis.call('EXISTS', KEYS[1]) == 0 or redis.call('ZSCORE', KEYS[2], KEYS[1]) then
+	return 0
+end
+touch(KEYS[1], KEYS[2], roomState(KEYS[1]), ARGV, 1)
+if redis.call('ZSCORE', KEYS[2], KEYS[1]) then
+	return 1
+end
+return 0
+`)
 
-	err = repo.rdb.HSet(repo.ctx, roomID, "status", "started").Err()
+// Назначить срок жизни комнатам, у которых его нет: они созданы до того, как
+// у комнат появились сроки, и иначе не удалились бы никогда. Возвращает,
+// скольким комнатам срок назначен.
+func (repo *RoomRepository) BackfillExpiries() (int, error) {
+	rooms, err := repo.ListRooms("")
 	if err != nil {
-		fmt.Printf("Error updating room status in Redis: %s\n", err)
-		return fmt.Errorf("failed to start game: %w", err)
+		return 0, err
+	}
+	scored := 0
+	for _, roomID := range rooms {
+		n, err := backfillExpiryScript.Run(repo.ctx, repo.rdb, []string{roomID, expiriesKey}, repo.ttl.expiryArgs(time.Now())...).Int()
+		if err != nil {
+			return scored, fmt.Errorf("failed to backfill expiries: %w", err)
+		}
+		scored += n
 	}
+	return scored, nil
+}
 
-	return nil
+// Получить комнаты, срок жизни которых истёк к моменту now
+func (repo *RoomRepository) DueExpiries(now time.Time) ([]string, error) {
+	rooms, err := repo.rdb.ZRangeByScore(repo.ctx, expiriesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch expiries: %w", err)
+	}
+	return rooms, nil
 }
 
-func (repo *RoomRepository) UpdateRoomField(roomID string, updates map[string]interface{}) error {
-	err := repo.rdb.HSet(repo.ctx, roomID, updates).Err()
+// expiredChannel — канал Redis, в котором экземпляры сервера узнают об удалённых по сроку комнатах
+const expiredChannel = "expired_rooms"
+
+// expireRoomScript удаляет комнату со всеми её ключами, только если срок её жизни
+// всё ещё истёк: комнату могли продлить после того, как она попала в DueExpiries.
+// Об удалении сразу сообщается в expiredChannel.
+// KEYS: сроки жизни комнат, сроки ходов, хеш комнаты, история ходов, разбор партии;
+// ARGV: комната, текущий момент, канал.
+var expireRoomScript = redis.NewScript(expiryLua + `
+local at = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not at or tonumber(at) > tonumber(ARGV[2]) then
+	return 0
+end
+local state = ''
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	state = roomState(KEYS[3])
+end
+redis.call('DEL', KEYS[3], KEYS[4], KEYS[5])
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('PUBLISH', ARGV[3], cjson.encode({roomID = ARGV[1], state = state}))
+return 1
+`)
+
+// Удалить истёкшую комнату; true получит только один экземпляр сервера
+func (repo *RoomRepository) ExpireRoom(roomID string, now time.Time) (bool, error) {
+	keys := []string{expiriesKey, deadlinesKey, roomID, movesKey(roomID), reportKey(roomID)}
+	n, err := expireRoomScript.Run(repo.ctx, repo.rdb, keys, roomID, now.UnixMilli(), expiredChannel).Int()
 	if err != nil {
-		return fmt.Errorf("failed to update room: %w", err)
+		return false, fmt.Errorf("failed to expire room: %w", err)
+	}
+	return n == 1, nil
+}
+
+// Получать удалённые по сроку комнаты, пока не отменён ctx
+func (repo *RoomRepository) WatchExpired(ctx context.Context, handle func(roomID string, state RoomState)) error {
+	sub := repo.rdb.Subscribe(ctx, expiredChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to expired rooms: %w", err)
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			var event struct {
+				RoomID string    `json:"roomID"`
+				State  RoomState `json:"state"`
+			}
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			handle(event.RoomID, event.State)
+		}
 	}
-	return nil
 }
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RoomState — этап жизни комнаты, хранится в поле state. Итог самой партии
+// (ongoing, finished, tie) по-прежнему хранится в поле status.
+type RoomState string
+
+const (
+	StateWaiting  RoomState = "waiting"  // Ждёт второго игрока
+	StateReady    RoomState = "ready"    // Оба игрока на месте, партия не начата
+	StateStarted  RoomState = "started"  // Партия идёт
+	StateFinished RoomState = "finished" // Партия закончена, можно сыграть реванш
+	StateArchived RoomState = "archived" // Комната закрыта, менять её нельзя
+)
+
+// transitions — из каких состояний комната может перейти в каждое
+var transitions = map[RoomState][]RoomState{
+	StateWaiting:  {StateReady, StateFinished}, // Второй игрок ушёл
+	StateReady:    {StateWaiting},              // Второй игрок пришёл
+	StateStarted:  {StateReady, StateFinished}, // Старт, реванш или отмена последнего хода
+	StateFinished: {StateStarted},
+	StateArchived: {StateFinished},
+}
+
+// CanTransition сообщает, разрешён ли переход между состояниями
+func CanTransition(from, to RoomState) bool {
+	for _, s := range transitions[to] {
+		if s == from {
+			return true
+		}
+	}
+	return false
+}
+
+// sources возвращает состояния, из которых разрешён переход, через запятую — для Lua
+func sources(to RoomState) string {
+	names := make([]string, len(transitions[to]))
+	for i, s := range transitions[to] {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ",")
+}
+
+// StateOf возвращает состояние комнаты. Комнаты, созданные до появления
+// поля state, получают его по статусу партии и наличию второго игрока.
+func StateOf(room map[string]string) RoomState {
+	if state := room["state"]; state != "" {
+		return RoomState(state)
+	}
+	switch room["status"] {
+	case "started", "ongoing":
+		return StateStarted
+	case "finished", "tie":
+		return StateFinished
+	}
+	if room["user2"] != "" {
+		return StateReady
+	}
+	return StateWaiting
+}
+
+var (
+	ErrRoomNotFound      = errors.New("room not found")
+	ErrRoomFull          = errors.New("room is full")
+	ErrNicknameTaken     = errors.New("nickname already in use")
+	ErrInvalidTransition = errors.New("room state does not allow this action")
+)
+
+// TransitionError — запрещённый переход комнаты из одного состояния в другое
+type TransitionError struct {
+	From RoomState
+	To   RoomState
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("room is %s, cannot become %s", e.From, e.To)
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrInvalidTransition)
+func (e *TransitionError) Unwrap() error { return ErrInvalidTransition }
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tic_tac_toe/internal/game"
+	"time"
+)
+
+// RoomStore — хранилище комнат. RoomRepository хранит их в Redis и годится
+// для нескольких экземпляров сервера, MemoryRepository — в памяти процесса
+// для локальной разработки и тестов.
+type RoomStore interface {
+	// CreateRoom создаёт комнату вместе с настройками, не относящимися к полю
+	CreateRoom(roomID, admin string, cfg game.Config, settings map[string]interface{}) error
+	JoinRoom(roomID, user string) error
+	LeaveRoom(roomID, user string) error
+	RemoveUser(roomID string) error
+	// StartGame начинает партию с полями fields и сроком deadline, нулевой — без срока
+	StartGame(roomID string, fields map[string]interface{}, deadline time.Time) error
+	ArchiveRoom(roomID string) error
+	DeleteRoom(roomID string) error
+
+	GetRoomInfo(roomID string) (map[string]string, error)
+	// ListRooms возвращает комнаты в состоянии state, пустое состояние — все
+	ListRooms(state RoomState) ([]string, error)
+
+	// UpdateRoom атомарно применяет изменение комнаты, в том числе ход. Любое
+	// изменение партии идёт через него, чтобы увеличить версию комнаты
+	UpdateRoom(roomID string, u RoomUpdate) (int, error)
+
+	GetMoves(roomID string) ([]Move, error)
+	// Разбор относится к партии gameID: разбор прошлой партии комнаты не
+	// сохраняется и не возвращается
+	SaveReport(roomID, gameID string, report []byte) error
+	GetReport(roomID, gameID string) ([]byte, error)
+	DeleteReport(roomID string) error
+
+	ScheduleDeadline(roomID string, at time.Time) error
+	CancelDeadline(roomID string) (bool, error)
+	DueDeadlines(now time.Time) ([]string, error)
+	// ClaimDeadline откладывает наступивший срок на lease: пока его обрабатывают,
+	// другие экземпляры его не видят, а необработанный срок наступит снова
+	ClaimDeadline(roomID string, now time.Time, lease time.Duration) (bool, error)
+
+	// Сроки жизни комнат по TTL: создание и UpdateRoom продлевают их сами
+	TouchRoom(roomID string) error
+	// BackfillExpiries назначает срок комнатам, у которых его нет, например,
+	// созданным до появления сроков, и возвращает их число
+	BackfillExpiries() (int, error)
+	DueExpiries(now time.Time) ([]string, error)
+	// ExpireRoom удаляет комнату, только если её срок жизни всё ещё истёк, и
+	// сообщает об удалении всем экземплярам сервера через WatchExpired
+	ExpireRoom(roomID string, now time.Time) (bool, error)
+	// WatchExpired вызывает handle для каждой удалённой по сроку комнаты, пока не отменён ctx
+	WatchExpired(ctx context.Context, handle func(roomID string, state RoomState)) error
+}
+
+var (
+	_ RoomStore = (*RoomRepository)(nil)
+	_ RoomStore = (*MemoryRepository)(nil)
+)
+
+// RoomIDPrefix — начало идентификатора любой комнаты
+const RoomIDPrefix = "room-"
+
+// ErrConflict возвращается, если комнату изменили после того, как её прочитали:
+// изменение посчитано для устаревшего состояния и не применено
+var ErrConflict = errors.New("room was changed by another request, try again")
+
+// AnyVersion в RoomUpdate.Version применяет изменение без проверки версии:
+// так меняют комнату переходы, которым достаточно проверки состояния
+const AnyVersion = "*"
+
+// RoomUpdate — изменение комнаты, которое применяется целиком или не применяется вовсе
+type RoomUpdate struct {
+	Version   string                 // Поле version прочитанной комнаты, на основе которой посчитано изменение
+	State     RoomState              // Новое состояние комнаты; пусто — состояние не меняется
+	Fields    map[string]interface{} // Новые значения полей комнаты
+	Move      *Move                  // Ход, который дописывается в историю
+	TrimMoves bool                   // Оставить в истории только первые KeepMoves ходов
+	KeepMoves int
+	// Назначить комнате срок Deadline, нулевой — снять срок. Срок пишется вместе
+	// с полями партии, поэтому не может разойтись с полем move_deadline.
+	SetDeadline bool
+	Deadline    time.Time
+}
+
+// Move — запись о ходе в истории комнаты
+type Move struct {
+	Seq      int       `json:"seq,omitempty"` // Номер хода с единицы, присваивается при записи
+	Player   string    `json:"player"`
+	Position int       `json:"position"`
+	Mark     string    `json:"mark"`
+	Time     time.Time `json:"timestamp"`
+}
+
+// newRoom возвращает поля только что созданной комнаты с настройками settings
+func newRoom(admin string, cfg game.Config, settings map[string]interface{}) (map[string]interface{}, error) {
+	g, err := game.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create room: %w", err)
+	}
+
+	room := map[string]interface{}{
+		"user1":      admin,
+		"user2":      "",
+		"admin":      admin,
+		"status":     "waiting", // waiting, started, ongoing, finished, tie
+		"state":      string(StateWaiting),
+		"board":      g.Board(), // Пробел — пустая клетка, строки поля (или подполя) идут подряд
+		"turn":       admin,     // Хранит, чей сейчас ход
+		"mode":       string(cfg.Mode),
+		"width":      cfg.Width,
+		"height":     cfg.Height,
+		"depth":      cfg.Depth, // Куб хранится послойно в той же строке board: 64 символа для 4x4x4
+		"win_length": cfg.WinLength,
+		"rules":      string(cfg.Rules), // standard, misere, wild, notakto, order_chaos
+		"next_board": g.Next(),          // Подполе для следующего хода в ультимативном режиме, -1 — любое
+		"sub_boards": g.SubBoards(),     // Итоги подполей в ультимативном режиме
+		"last_move":  -1,                // Клетка последнего хода
+		"first":      admin,             // Кто ходит первым в текущей партии
+		"version":    0,                 // Растёт с каждым изменением партии, см. UpdateRoom
+	}
+	for field, value := range settings {
+		room[field] = value
+	}
+	return room, nil
+}
+
+// Переходы комнаты одинаковы для любого хранилища: каждый выполняется одним
+// вызовом UpdateRoom, который и проверяет, разрешён ли он.
+
+// joinRoom занимает место второго игрока, пока комната ждёт
+func joinRoom(s RoomStore, roomID, user string) error {
+	room, err := s.GetRoomInfo(roomID)
+	if err != nil {
+		return err
+	}
+	// Создатель комнаты не меняется, поэтому имя можно проверить до перехода
+	if user == room["user1"] {
+		return ErrNicknameTaken
+	}
+
+	_, err = s.UpdateRoom(roomID, RoomUpdate{
+		Version: AnyVersion,
+		State:   StateReady,
+		Fields:  map[string]interface{}{"user2": user},
+	})
+	var te *TransitionError
+	if errors.As(err, &te) && te.From != StateArchived {
+		return ErrRoomFull
+	}
+	return err
+}
+
+// removeUser освобождает место второго игрока. Посреди партии это запрещено;
+// после неё комната снова ждёт соперника, а счёт серии обнуляется.
+func removeUser(s RoomStore, roomID string) error {
+	_, err := s.UpdateRoom(roomID, RoomUpdate{
+		Version: AnyVersion,
+		State:   StateWaiting,
+		Fields: map[string]interface{}{
+			"user2":         "",
+			"rematch_offer": "",
+			"series_status": "ongoing",
+			"series_winner": "",
+			"series_wins1":  0,
+			"series_wins2":  0,
+			"series_draws":  0,
+		},
+	})
+	return err
+}
+
+// leaveRoom выводит пользователя из комнаты
+func leaveRoom(s RoomStore, roomID, user string) error {
+	room, err := s.GetRoomInfo(roomID)
+	if err != nil {
+		return err
+	}
+
+	if room["user1"] == user {
+		// Если выходит создатель комнаты, удаляем всю комнату
+		return s.DeleteRoom(roomID)
+	} else if room["user2"] == user {
+		// Если выходит второй игрок, просто очищаем user2
+		return s.RemoveUser(roomID)
+	}
+
+	return fmt.Errorf("user not found in room")
+}
+
+// startGame начинает партию, когда оба игрока на месте. fields — состояние новой партии:
+// комната могла уже видеть партию с прежним соперником, её история стирается.
+// deadline — срок первого хода, назначается той же записью.
+func startGame(s RoomStore, roomID string, fields map[string]interface{}, deadline time.Time) error {
+	updates := map[string]interface{}{"status": "started"}
+	for field, value := range fields {
+		updates[field] = value
+	}
+	_, err := s.UpdateRoom(roomID, RoomUpdate{
+		Version:     AnyVersion,
+		State:       StateStarted,
+		Fields:      updates,
+		TrimMoves:   true,
+		SetDeadline: true,
+		Deadline:    deadline,
+	})
+	var te *TransitionError
+	if errors.As(err, &te) && te.From == StateWaiting {
+		return fmt.Errorf("cannot start game, room is not full: %w", err)
+	}
+	return err
+}
+
+// archiveRoom переносит законченную комнату в архив; после этого её нельзя изменить
+func archiveRoom(s RoomStore, roomID string) error {
+	_, err := s.UpdateRoom(roomID, RoomUpdate{Version: AnyVersion, State: StateArchived})
+	return err
+}
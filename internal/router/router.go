@@ -2,35 +2,55 @@ package router
 
 import (
 	"context"
+	"tic_tac_toe/internal/archive"
+	"tic_tac_toe/internal/bot"
 	"tic_tac_toe/internal/handler"
 	"tic_tac_toe/internal/repository"
 	"tic_tac_toe/pkg/logger"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 )
 
-func SetupRoutes(e *echo.Echo, rdb *redis.Client, Logger logger.Logger) {
-	// Создаём репозиторий и хендлер
-	repo := repository.NewRoomRepository(rdb, context.Background())
+func SetupRoutes(e *echo.Echo, repo repository.RoomStore, games *archive.Archive, Logger logger.Logger, botCfg bot.Config) {
+	// Без дебютной книги боты просто думают над каждым ходом
+	book, err := bot.LoadBook(botCfg.Book)
+	if err != nil {
+		Logger.Error(context.Background(), "failed to load opening book: "+err.Error())
+	}
+	bots := bot.NewPool(botCfg, book)
 	roomHandler := &handler.RoomHandler{
-		Repo:   repo,
-		Logger: Logger,
+		Repo:    repo,
+		Logger:  Logger,
+		Bots:    bots,
+		Archive: games,
 	}
 
 	webSocketHandler := &handler.WebSocketHandler{
 		Repo:    repo,
 		Logger:  Logger,
 		Clients: make(map[string][]*websocket.Conn),
+		Bots:    bots,
+		Archive: games,
 	}
 
+	// Флаги падают и без входящих сообщений: сроки комнат проверяются в фоне
+	go webSocketHandler.WatchDeadlines(context.Background())
+	// Заброшенные комнаты удаляются по истечении срока жизни
+	go webSocketHandler.WatchExpiries(context.Background())
+
 	e.POST("/room/create", roomHandler.CreateRoom)
 	e.POST("/room/join", roomHandler.JoinRoom)
 	e.GET("/room/info/:room_id", roomHandler.GetRoomInfo)
+	e.GET("/rooms", roomHandler.ListRooms)
 	e.DELETE("/room/delete", roomHandler.DeleteRoom)
 	e.POST("/room/delete/user", roomHandler.RemoveUser)
+	e.POST("/room/archive", roomHandler.ArchiveRoom)
 	e.GET("/room/start/:room_id", roomHandler.StartGame)
+	e.GET("/room/:room_id/analysis", roomHandler.GetAnalysis)
+	e.GET("/room/:room_id/report", roomHandler.GetReport)
+	e.GET("/games", roomHandler.ListGames)
+	e.GET("/games/:game_id", roomHandler.GetGame)
 
 	e.GET("/ws/:room_id", webSocketHandler.HandleConnection)
 }
@@ -7,20 +7,22 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"tic_tac_toe/internal/archive"
+	"tic_tac_toe/internal/bot"
 	"tic_tac_toe/internal/handler"
+	"tic_tac_toe/internal/repository"
 	"tic_tac_toe/internal/router"
 	"tic_tac_toe/pkg/logger"
 	"time"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/go-redis/redis/v8"
 	"github.com/labstack/echo/v4"
 )
 
-func New(rdb *redis.Client, Logger logger.Logger) *echo.Echo {
+func New(repo repository.RoomStore, games *archive.Archive, Logger logger.Logger, botCfg bot.Config) *echo.Echo {
 	e := echo.New()
 	e.Validator = &handler.CustomValidator{Validator: validator.New()}
-	router.SetupRoutes(e, rdb, Logger)
+	router.SetupRoutes(e, repo, games, Logger, botCfg)
 
 	return e
 }